@@ -3,24 +3,38 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/api"
-	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/grafana"
-	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/portainer"
-	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/prometheus"
-	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/silverbullet"
-	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/vikunja"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/audit"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/health"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/logging"
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/policy"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/prompts"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/ratelimit"
 )
 
 func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run starts the server and blocks until it stops. Errors are returned
+// rather than fatal, so that deferred cleanup such as flushing the audit
+// log happens before the process exits.
+func run() error {
 	// Get environment (default: dev)
 	env := os.Getenv("ENV")
 	if env == "" {
@@ -30,7 +44,7 @@ func main() {
 	// Load configuration
 	cfg, err := config.Load(env)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Create MCP server
@@ -39,74 +53,61 @@ func main() {
 		cfg.Server.Version,
 		cfg.Server.ProtocolVersion,
 	)
-
-	// Register Portainer tools
-	if cfg.Portainer.Enabled && cfg.Portainer.URL != "" {
-		portainerClient := portainer.NewClient(
-			cfg.Portainer.URL,
-			cfg.Portainer.Token,
-			cfg.Timeout.HTTP,
-			cfg.TLS.SkipVerify,
-		)
-		portainer.RegisterTools(mcpServer, portainerClient)
-		log.Printf("✓ Portainer tools registered (%s)", cfg.Portainer.URL)
-	} else {
-		log.Println("⊗ Portainer disabled or not configured")
+	// Log through slog at the configured level and format; records are
+	// also forwarded to MCP clients that ask for them
+	logger, err := logging.New(cfg.Log, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("invalid log config: %w", err)
 	}
-
-	// Register Grafana tools
-	if cfg.Grafana.Enabled && cfg.Grafana.URL != "" {
-		grafanaClient := grafana.NewClient(
-			cfg.Grafana.URL,
-			cfg.Grafana.Token,
-			cfg.Timeout.HTTP,
-			cfg.TLS.SkipVerify,
-		)
-		grafana.RegisterTools(mcpServer, grafanaClient)
-		log.Printf("✓ Grafana tools registered (%s)", cfg.Grafana.URL)
-	} else {
-		log.Println("⊗ Grafana disabled or not configured")
+	mcpServer.SetLogger(logger)
+	slog.SetDefault(mcpServer.Logger())
+
+	mcpServer.SetMaxInFlight(cfg.Server.MaxInFlight)
+	mcpServer.SetPageSize(cfg.Server.PageSize)
+	mcpServer.SetAllowDestructive(cfg.Server.AllowDestructiveTools)
+	mcpServer.SetConfirmDestructive(cfg.Server.ConfirmDestructiveTools)
+	mcpServer.SetPollInterval(cfg.Server.ResourcePollInterval)
+	mcpServer.SetClientRequestTimeout(cfg.Server.ClientRequestTimeout)
+
+	// Record every tool call in the audit log
+	if cfg.Audit.File != "" || cfg.Audit.WebhookURL != "" {
+		auditLog, err := audit.New(cfg.Audit, mcpServer.Logger())
+		if err != nil {
+			return fmt.Errorf("invalid audit config: %w", err)
+		}
+		mcpServer.SetToolCallAuditor(auditLog)
+		defer auditLog.Close(5 * time.Second)
+		log.Printf("✓ Tool calls audited (file %q, webhook %t)", cfg.Audit.File, cfg.Audit.WebhookURL != "")
 	}
 
-	// Register Prometheus tools
-	if cfg.Prometheus.Enabled && cfg.Prometheus.URL != "" {
-		prometheusClient := prometheus.NewClient(
-			cfg.Prometheus.URL,
-			cfg.Timeout.HTTP,
-			cfg.TLS.SkipVerify,
-		)
-		prometheus.RegisterTools(mcpServer, prometheusClient)
-		log.Printf("✓ Prometheus tools registered (%s)", cfg.Prometheus.URL)
-	} else {
-		log.Println("⊗ Prometheus disabled or not configured")
+	// Rate limit tool calls and cap the requests in flight to each backend
+	mcpServer.SetToolCallLimiter(ratelimit.New(cfg.Limits))
+	backends := ratelimit.NewBackends(cfg.Limits)
+
+	// Register the tools, resources and completions of enabled backends
+	services := newServices(backends)
+	for _, svc := range services {
+		svc.apply(mcpServer, cfg)
 	}
 
-	// Register SilverBullet tools
-	if cfg.SilverBullet.Enabled && cfg.SilverBullet.URL != "" {
-		silverbulletClient := silverbullet.NewClient(
-			cfg.SilverBullet.URL,
-			cfg.SilverBullet.Token,
-			cfg.Timeout.HTTP,
-			cfg.TLS.SkipVerify,
-		)
-		silverbullet.RegisterTools(mcpServer, silverbulletClient)
-		log.Printf("✓ SilverBullet tools registered (%s)", cfg.SilverBullet.URL)
-	} else {
-		log.Println("⊗ SilverBullet disabled or not configured")
+	// Register prompt templates
+	if cfg.Server.PromptsDir != "" {
+		templates, err := prompts.LoadDir(cfg.Server.PromptsDir)
+		if err != nil {
+			return fmt.Errorf("failed to load prompts: %w", err)
+		}
+		prompts.Register(mcpServer, templates)
+		log.Printf("✓ %d prompts registered (%s)", len(templates), cfg.Server.PromptsDir)
 	}
 
-	// Register Vikunja tools
-	if cfg.Vikunja.Enabled && cfg.Vikunja.URL != "" {
-		vikunjaClient := vikunja.NewClient(
-			cfg.Vikunja.URL,
-			cfg.Vikunja.Token,
-			cfg.Timeout.HTTP,
-			cfg.TLS.SkipVerify,
-		)
-		vikunja.RegisterTools(mcpServer, vikunjaClient)
-		log.Printf("✓ Vikunja tools registered (%s)", cfg.Vikunja.URL)
-	} else {
-		log.Println("⊗ Vikunja disabled or not configured")
+	// Check tool calls against the policy file
+	if cfg.Server.PolicyFile != "" {
+		p, err := policy.Load(cfg.Server.PolicyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load policy: %w", err)
+		}
+		mcpServer.SetToolPolicy(p)
+		log.Printf("✓ %d policy rules loaded (%s)", p.Len(), cfg.Server.PolicyFile)
 	}
 
 	log.Println("========================================")
@@ -127,6 +128,76 @@ func main() {
 		cancel()
 	}()
 
+	// Create HTTP API server if enabled; it is started below
+	var apiServer *api.APIServer
+	if cfg.Server.APIEnabled {
+		tokens, err := auth.NewStore(cfg.Auth, cfg.Server.APIToken)
+		if err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		oauth, err := newOAuthVerifier(cfg.Auth.OAuth)
+		if err != nil {
+			return fmt.Errorf("invalid OAuth config: %w", err)
+		}
+		if tokens.Len() == 0 && oauth == nil {
+			return errors.New("API server enabled but no API tokens configured (set APP_SERVER__API_TOKEN, auth.tokens or auth.oauth)")
+		}
+		apiServer = api.NewAPIServer(cfg.Server.APIPort, tokens, cfg.Server.AllowedOrigins, mcpServer, mcpServer.Logger())
+		apiServer.SetOAuth(oauth)
+		if oauth != nil {
+			log.Printf("✓ OAuth access tokens accepted from %s", cfg.Auth.OAuth.Issuer)
+		}
+	}
+
+	// Reload the configuration on SIGHUP, adding and removing backends
+	// that were enabled or disabled and replacing the API tokens, the
+	// tool policy and the limits
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+	go func() {
+		for range reloadChan {
+			log.Println("Received SIGHUP, reloading configuration...")
+			newCfg, err := config.Load(env)
+			if err != nil {
+				log.Printf("Failed to reload config: %v", err)
+				continue
+			}
+			backends.SetLimits(newCfg.Limits)
+			mcpServer.SetToolCallLimiter(ratelimit.New(newCfg.Limits))
+			for _, svc := range services {
+				svc.apply(mcpServer, newCfg)
+			}
+			if newCfg.Server.PolicyFile == "" {
+				mcpServer.SetToolPolicy(nil)
+			} else if p, err := policy.Load(newCfg.Server.PolicyFile); err != nil {
+				log.Printf("Keeping tool policy, failed to load policy: %v", err)
+			} else {
+				mcpServer.SetToolPolicy(p)
+				log.Printf("✓ %d policy rules loaded (%s)", p.Len(), newCfg.Server.PolicyFile)
+			}
+			if apiServer != nil {
+				tokens, err := auth.NewStore(newCfg.Auth, newCfg.Server.APIToken)
+				oauth, oauthErr := newOAuthVerifier(newCfg.Auth.OAuth)
+				switch {
+				case err != nil:
+					log.Printf("Keeping API tokens, invalid auth config: %v", err)
+				case oauthErr != nil:
+					log.Printf("Keeping API tokens, invalid OAuth config: %v", oauthErr)
+				case tokens.Len() == 0 && oauth == nil:
+					log.Println("Keeping API tokens, none configured")
+				default:
+					apiServer.SetTokens(tokens)
+					apiServer.SetOAuth(oauth)
+					log.Printf("✓ %d API tokens loaded", tokens.Len())
+				}
+			}
+		}
+	}()
+
+	// Poll subscribed resources and notify subscribers of changes
+	go mcpServer.WatchResources(ctx)
+
 	// Start HTTP health server if enabled
 	if cfg.Server.HTTPEnabled {
 		healthServer := health.NewHealthServer(cfg.Server.HTTPPort)
@@ -139,11 +210,7 @@ func main() {
 	}
 
 	// Start HTTP API server if enabled
-	if cfg.Server.APIEnabled {
-		if cfg.Server.APIToken == "" {
-			log.Fatal("API server enabled but APP_SERVER__API_TOKEN not set")
-		}
-		apiServer := api.NewAPIServer(cfg.Server.APIPort, cfg.Server.APIToken, mcpServer, log.Default())
+	if apiServer != nil {
 		go func() {
 			log.Printf("Starting MCP API server on port %d", cfg.Server.APIPort)
 			if err := apiServer.Start(ctx); err != nil && err != http.ErrServerClosed {
@@ -155,10 +222,20 @@ func main() {
 	// Run MCP server (stdio transport) - blocks indefinitely in Docker mode
 	log.Println("MCP Server starting (stdio transport)...")
 	if err := mcpServer.Run(ctx); err != nil {
-		log.Fatalf("Server error: %v", err)
+		return fmt.Errorf("server error: %w", err)
 	}
 
 	log.Println("MCP Server stopped")
+	return nil
+}
+
+// newOAuthVerifier returns the verifier of OAuth access tokens, or nil
+// when no issuer is configured
+func newOAuthVerifier(cfg config.OAuthConfig) (*auth.OAuthVerifier, error) {
+	if cfg.Issuer == "" {
+		return nil, nil
+	}
+	return auth.NewOAuthVerifier(cfg)
 }
 
 // test ci-cd 1-21-26 07
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"log"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/grafana"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/portainer"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/prometheus"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/silverbullet"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/clients/vikunja"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/ratelimit"
+)
+
+// service is a backend whose tools, resources and completions are
+// registered while it is enabled and removed when it is disabled
+type service struct {
+	name   string // display name, e.g. "Portainer"
+	prefix string // prefix of its tool names and resource URI scheme
+
+	config   func(cfg *config.Config) config.ServiceConfig
+	register func(server *mcp.Server, cfg *config.Config)
+
+	applied bool
+	active  bool
+	current config.ServiceConfig
+}
+
+// newServices returns the backends the server can expose. Requests to each
+// backend are capped by backends.
+func newServices(backends *ratelimit.Backends) []*service {
+	return []*service{
+		{
+			name:   "Portainer",
+			prefix: "portainer",
+			config: func(cfg *config.Config) config.ServiceConfig { return cfg.Portainer },
+			register: func(server *mcp.Server, cfg *config.Config) {
+				client := portainer.NewClient(cfg.Portainer.URL, cfg.Portainer.Token, cfg.Timeout.HTTP, cfg.TLS.SkipVerify)
+				client.WrapTransport(backends.Transport("portainer"))
+				portainer.RegisterTools(server, client)
+				portainer.RegisterResources(server, client)
+				portainer.RegisterCompletions(server, client)
+				portainer.RegisterAttributes(server, client)
+			},
+		},
+		{
+			name:   "Grafana",
+			prefix: "grafana",
+			config: func(cfg *config.Config) config.ServiceConfig { return cfg.Grafana },
+			register: func(server *mcp.Server, cfg *config.Config) {
+				client := grafana.NewClient(cfg.Grafana.URL, cfg.Grafana.Token, cfg.Timeout.HTTP, cfg.TLS.SkipVerify)
+				client.WrapTransport(backends.Transport("grafana"))
+				grafana.RegisterTools(server, client)
+				grafana.RegisterResources(server, client)
+				grafana.RegisterCompletions(server, client)
+			},
+		},
+		{
+			name:   "Prometheus",
+			prefix: "prometheus",
+			config: func(cfg *config.Config) config.ServiceConfig { return cfg.Prometheus },
+			register: func(server *mcp.Server, cfg *config.Config) {
+				client := prometheus.NewClient(cfg.Prometheus.URL, cfg.Timeout.HTTP, cfg.TLS.SkipVerify)
+				client.WrapTransport(backends.Transport("prometheus"))
+				prometheus.RegisterTools(server, client)
+				prometheus.RegisterResources(server, client)
+				prometheus.RegisterCompletions(server, client)
+			},
+		},
+		{
+			name:   "SilverBullet",
+			prefix: "silverbullet",
+			config: func(cfg *config.Config) config.ServiceConfig { return cfg.SilverBullet },
+			register: func(server *mcp.Server, cfg *config.Config) {
+				client := silverbullet.NewClient(cfg.SilverBullet.URL, cfg.SilverBullet.Token, cfg.Timeout.HTTP, cfg.TLS.SkipVerify)
+				client.WrapTransport(backends.Transport("silverbullet"))
+				silverbullet.RegisterTools(server, client)
+				silverbullet.RegisterResources(server, client)
+				silverbullet.RegisterCompletions(server, client)
+			},
+		},
+		{
+			name:   "Vikunja",
+			prefix: "vikunja",
+			config: func(cfg *config.Config) config.ServiceConfig { return cfg.Vikunja },
+			register: func(server *mcp.Server, cfg *config.Config) {
+				client := vikunja.NewClient(cfg.Vikunja.URL, cfg.Vikunja.Token, cfg.Timeout.HTTP, cfg.TLS.SkipVerify)
+				client.WrapTransport(backends.Transport("vikunja"))
+				vikunja.RegisterTools(server, client)
+				vikunja.RegisterResources(server, client)
+				vikunja.RegisterCompletions(server, client)
+			},
+		},
+	}
+}
+
+// apply registers or removes the service's tools and resources to match
+// cfg. A service whose URL or token changed is registered again with a new
+// client. Connected clients are notified of the changes by the server.
+func (svc *service) apply(server *mcp.Server, cfg *config.Config) {
+	next := svc.config(cfg)
+	enabled := next.Enabled && next.URL != ""
+
+	if svc.active && (!enabled || next != svc.current) {
+		tools := server.UnregisterToolsWithPrefix(svc.prefix + "_")
+		resources := server.UnregisterResourcesWithPrefix(svc.prefix + "://")
+		svc.active = false
+		log.Printf("⊗ %s removed (%d tools, %d resources)", svc.name, tools, resources)
+	}
+
+	if enabled && !svc.active {
+		svc.register(server, cfg)
+		svc.active, svc.current = true, next
+		log.Printf("✓ %s tools and resources registered (%s)", svc.name, next.URL)
+	} else if !enabled && !svc.applied {
+		log.Printf("⊗ %s disabled or not configured", svc.name)
+	}
+	svc.applied = true
+}
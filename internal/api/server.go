@@ -3,33 +3,54 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/metrics"
 )
 
 // APIServer provides HTTP JSON-RPC interface to MCP server
 type APIServer struct {
-	port      int
-	apiToken  string
-	mcpServer *mcp.Server
-	server    *http.Server
-	logger    *log.Logger
+	port           int
+	tokens         atomic.Pointer[auth.Store]
+	oauth          atomic.Pointer[auth.OAuthVerifier]
+	allowedOrigins []string
+	mcpServer      *mcp.Server
+	server         *http.Server
+	logger         *slog.Logger
 }
 
-// NewAPIServer creates a new API server
-func NewAPIServer(port int, apiToken string, mcpServer *mcp.Server, logger *log.Logger) *APIServer {
-	return &APIServer{
-		port:      port,
-		apiToken:  apiToken,
-		mcpServer: mcpServer,
-		logger:    logger,
+// NewAPIServer creates a new API server accepting the tokens in store
+func NewAPIServer(port int, tokens *auth.Store, allowedOrigins []string, mcpServer *mcp.Server, logger *slog.Logger) *APIServer {
+	a := &APIServer{
+		port:           port,
+		allowedOrigins: allowedOrigins,
+		mcpServer:      mcpServer,
+		logger:         logger,
 	}
+	a.tokens.Store(tokens)
+	return a
+}
+
+// SetTokens replaces the accepted tokens, e.g. after a configuration
+// reload. Requests already authenticated are not affected.
+func (a *APIServer) SetTokens(tokens *auth.Store) {
+	a.tokens.Store(tokens)
+}
+
+// SetOAuth makes the server accept access tokens validated by v, in
+// addition to the tokens in its store, and publish v's protected resource
+// metadata. A nil v turns OAuth off.
+func (a *APIServer) SetOAuth(v *auth.OAuthVerifier) {
+	a.oauth.Store(v)
 }
 
 // Start starts the HTTP API server
@@ -42,6 +63,15 @@ func (a *APIServer) Start(ctx context.Context) error {
 	// Tools list endpoint (convenience)
 	mux.HandleFunc("/api/mcp/v1/tools", a.authMiddleware(a.handleListTools))
 
+	// Native MCP endpoint (Streamable HTTP transport)
+	streamable := mcp.NewStreamableHTTPHandler(a.mcpServer, a.allowedOrigins)
+	mux.HandleFunc("/mcp", a.authMiddleware(streamable.ServeHTTP))
+
+	// OAuth protected resource metadata, at the well-known path and at the
+	// well-known path followed by the resource path
+	mux.HandleFunc(auth.ProtectedResourcePath, a.handleResourceMetadata)
+	mux.HandleFunc(auth.ProtectedResourcePath+"/", a.handleResourceMetadata)
+
 	a.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", a.port),
 		Handler: mux,
@@ -55,89 +85,199 @@ func (a *APIServer) Start(ctx context.Context) error {
 		a.server.Shutdown(shutdownCtx)
 	}()
 
-	a.logger.Printf("MCP API server starting on port %d", a.port)
+	a.logger.Info("MCP API server starting", "port", a.port)
 	return a.server.ListenAndServe()
 }
 
-// authMiddleware validates Bearer token
+// authMiddleware validates the Bearer token and attaches its principal to
+// the request, so that the dispatcher only allows the tools and resources
+// the token's scopes cover
 func (a *APIServer) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			a.sendError(w, http.StatusUnauthorized, -32000, "Missing Authorization header", nil)
+			a.challenge(w, "")
+			a.sendError(w, http.StatusUnauthorized, mcp.Unauthorized, "Missing Authorization header", nil)
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			a.sendError(w, http.StatusUnauthorized, -32000, "Invalid Authorization header format", nil)
+			a.challenge(w, "invalid_request")
+			a.sendError(w, http.StatusUnauthorized, mcp.Unauthorized, "Invalid Authorization header format", nil)
 			return
 		}
 
-		token := parts[1]
-		if token != a.apiToken {
-			a.sendError(w, http.StatusUnauthorized, -32000, "Invalid API token", nil)
+		principal, err := a.authenticate(r.Context(), parts[1])
+		if err != nil {
+			a.logger.Warn("Rejected API request", "path", r.URL.Path, "error", err)
+			a.challenge(w, "invalid_token")
+			a.sendError(w, http.StatusUnauthorized, mcp.Unauthorized, err.Error(), nil)
 			return
 		}
 
 		metrics.ActiveConnections.Inc()
 		defer metrics.ActiveConnections.Dec()
 
-		next(w, r)
+		next(w, r.WithContext(mcp.WithAuthorizer(r.Context(), principal)))
+	}
+}
+
+// authenticate looks token up in the store and, when it is not there and
+// OAuth is on, validates it as an access token
+func (a *APIServer) authenticate(ctx context.Context, token string) (*auth.Principal, error) {
+	principal, err := a.tokens.Load().Authenticate(token)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		if v := a.oauth.Load(); v != nil {
+			return v.Verify(ctx, token)
+		}
+	}
+	return principal, err
+}
+
+// challenge sets the WWW-Authenticate header of a 401 response. With OAuth
+// on it names the protected resource metadata, from which clients find the
+// authorization server.
+func (a *APIServer) challenge(w http.ResponseWriter, errorCode string) {
+	var params []string
+	if v := a.oauth.Load(); v != nil {
+		params = append(params, fmt.Sprintf("resource_metadata=%q", v.MetadataURL()))
+	}
+	if errorCode != "" {
+		params = append(params, fmt.Sprintf("error=%q", errorCode))
+	}
+	value := "Bearer"
+	if len(params) > 0 {
+		value += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", value)
+}
+
+// handleResourceMetadata serves the OAuth protected resource metadata. It
+// needs no token, and is not found while OAuth is off.
+func (a *APIServer) handleResourceMetadata(w http.ResponseWriter, r *http.Request) {
+	v := a.oauth.Load()
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		a.sendError(w, http.StatusMethodNotAllowed, mcp.ServerError, "Method not allowed", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(v.Metadata())
+}
+
+// maxRequestSize bounds the body of a /api/mcp/v1/call request
+const maxRequestSize = 4 << 20
+
+// rpcTransport is the request/response transport behind /api/mcp/v1/call.
+// Each call is answered in its own HTTP response. When the client accepts
+// text/event-stream the response is streamed, and server-initiated messages
+// such as progress notifications are delivered ahead of it.
+type rpcTransport struct {
+	stream *mcp.SSEWriter
+}
+
+func (t *rpcTransport) Name() string {
+	return "http"
+}
+
+func (t *rpcTransport) Send(ctx context.Context, msg interface{}) error {
+	if t.stream == nil {
+		return mcp.ErrNoBackChannel
 	}
+	return t.stream.Send(msg)
 }
 
 // handleRPCCall handles JSON-RPC method calls
 func (a *APIServer) handleRPCCall(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		a.sendError(w, http.StatusMethodNotAllowed, -32000, "Method not allowed", nil)
+		a.sendError(w, http.StatusMethodNotAllowed, mcp.ServerError, "Method not allowed", nil)
 		return
 	}
 
-	startTime := time.Now()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		a.sendError(w, http.StatusBadRequest, mcp.ParseError, "Parse error", err.Error())
+		return
+	}
 
 	var req mcp.JSONRPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		a.sendError(w, http.StatusBadRequest, -32700, "Parse error", err.Error())
-		return
+	batch := mcp.IsBatch(body)
+	if !batch {
+		if err := json.Unmarshal(body, &req); err != nil {
+			a.sendError(w, http.StatusBadRequest, mcp.ParseError, "Parse error", err.Error())
+			return
+		}
 	}
 
-	// Handle the RPC request via MCP server
-	result, err := a.mcpServer.HandleHTTPRequest(r.Context(), &req)
-	duration := time.Since(startTime)
+	transport := &rpcTransport{}
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		transport.stream, _ = mcp.NewSSEWriter(w)
+	}
 
-	if err != nil {
-		metrics.RecordRPCRequest(req.Method, "http", duration, "error")
-		a.sendError(w, http.StatusInternalServerError, -32603, "Internal error", err.Error())
+	// Handle the RPC request (or batch) via the shared MCP dispatcher. Each
+	// call stands alone, so no initialize handshake is needed, but
+	// initialize is answered as on stdio.
+	sess := a.mcpServer.NewStatelessSession(transport)
+
+	var out interface{}
+	if batch {
+		out = a.mcpServer.DispatchBatch(r.Context(), sess, body)
+	} else if resp := a.mcpServer.Dispatch(r.Context(), sess, &req); resp != nil {
+		out = resp
+	}
+
+	if transport.stream != nil {
+		if out != nil {
+			if err := transport.stream.Send(out); err != nil {
+				a.logger.Warn("Failed to write SSE response", "error", err)
+			}
+		}
 		return
 	}
 
-	metrics.RecordRPCRequest(req.Method, "http", duration, "")
+	if out == nil {
+		// Only notifications, nothing to return
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      req.ID,
-		"result":  result,
-	})
+	json.NewEncoder(w).Encode(out)
 }
 
-// handleListTools returns available tools
+// handleListTools returns the tools the caller's token allows, a page at a
+// time like tools/list. The cursor query parameter selects the page after
+// the first.
 func (a *APIServer) handleListTools(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		a.sendError(w, http.StatusMethodNotAllowed, -32000, "Method not allowed", nil)
+		a.sendError(w, http.StatusMethodNotAllowed, mcp.ServerError, "Method not allowed", nil)
 		return
 	}
 
-	tools := a.mcpServer.GetTools()
+	tools, next, err := a.mcpServer.ListTools(r.Context(), r.URL.Query().Get("cursor"))
+	if err != nil {
+		a.sendError(w, http.StatusBadRequest, mcp.InvalidParams, "Invalid params", err.Error())
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	body := map[string]interface{}{
 		"tools": tools,
 		"count": len(tools),
-	})
+	}
+	if next != "" {
+		body["nextCursor"] = next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(body)
 }
 
 // sendError sends JSON-RPC error response
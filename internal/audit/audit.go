@@ -0,0 +1,172 @@
+// Package audit records every tool call: when it was made, over which
+// transport, by whom, with what arguments, how long it took and how it
+// ended. Records are appended as JSON lines to a rotating file and can also
+// be sent to a webhook. Argument values whose names match the redaction
+// list are replaced before anything is written.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// Redacted replaces the values of redacted arguments
+const Redacted = "[REDACTED]"
+
+// Entry is one audit record, written as a JSON line
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	Transport  string                 `json:"transport"`
+	Caller     string                 `json:"caller"` // as auth.CallerIdentity
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	DurationMS float64                `json:"duration_ms"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Logger writes audit records. It implements mcp.ToolCallAuditor.
+type Logger struct {
+	redact []string
+
+	// mu guards file, which is nil once closed
+	mu   sync.Mutex
+	file io.WriteCloser
+
+	webhook *webhook
+	logger  *slog.Logger
+}
+
+// New creates a logger for cfg. It returns an error when cfg names neither
+// a file nor a webhook, or a redaction pattern is invalid.
+func New(cfg config.AuditConfig, logger *slog.Logger) (*Logger, error) {
+	if cfg.File == "" && cfg.WebhookURL == "" {
+		return nil, errors.New("audit needs a file or a webhook_url")
+	}
+
+	l := &Logger{logger: logger}
+	for _, pattern := range cfg.Redact {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q", pattern)
+		}
+		l.redact = append(l.redact, pattern)
+	}
+
+	if cfg.File != "" {
+		l.file = &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+	}
+	if cfg.WebhookURL != "" {
+		l.webhook = newWebhook(cfg.WebhookURL, cfg.WebhookToken, logger)
+	}
+	return l, nil
+}
+
+// AuditToolCall implements mcp.ToolCallAuditor
+func (l *Logger) AuditToolCall(ctx context.Context, record mcp.ToolCallRecord) {
+	entry := Entry{
+		Time:       record.Time.UTC(),
+		Transport:  record.Transport,
+		Caller:     auth.CallerIdentity(ctx),
+		Tool:       record.Tool,
+		Arguments:  l.redactMap(record.Arguments),
+		DurationMS: float64(record.Duration.Microseconds()) / 1000,
+		Outcome:    record.Outcome,
+		Error:      record.Error,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		l.logger.Error("Failed to encode audit record", "tool", record.Tool, "error", err)
+		return
+	}
+
+	l.mu.Lock()
+	if l.file != nil {
+		if _, err := l.file.Write(append(line, '\n')); err != nil {
+			l.logger.Error("Failed to write audit record", "tool", record.Tool, "error", err)
+		}
+	}
+	l.mu.Unlock()
+	if l.webhook != nil {
+		l.webhook.send(line)
+	}
+}
+
+// Close sends the records still queued for the webhook, waiting up to
+// timeout, and closes the file
+func (l *Logger) Close(timeout time.Duration) error {
+	if l.webhook != nil {
+		l.webhook.close(timeout)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// redacted reports whether the value of the argument name is redacted
+func (l *Logger) redacted(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range l.redact {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactMap copies m with the values of redacted keys replaced, at any
+// depth
+func (l *Logger) redactMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if l.redacted(k) {
+			out[k] = Redacted
+			continue
+		}
+		out[k] = l.redactValue(v)
+	}
+	return out
+}
+
+func (l *Logger) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return l.redactMap(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = l.redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
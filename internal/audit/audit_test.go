@@ -0,0 +1,195 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+func newTestLogger(t *testing.T, redact ...string) (*Logger, string) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "audit.log")
+	l, err := New(config.AuditConfig{File: file, Redact: redact}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return l, file
+}
+
+func TestRedactMap(t *testing.T) {
+	l, _ := newTestLogger(t, "password", "*TOKEN*", "api_?ey")
+
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      map[string]interface{}
+	}{
+		{"nil", nil, nil},
+		{
+			"exact name",
+			map[string]interface{}{"password": "hunter2", "user": "alice"},
+			map[string]interface{}{"password": Redacted, "user": "alice"},
+		},
+		{
+			"case is ignored",
+			map[string]interface{}{"Password": "hunter2", "AUTH_TOKEN": "abc"},
+			map[string]interface{}{"Password": Redacted, "AUTH_TOKEN": Redacted},
+		},
+		{
+			"wildcards",
+			map[string]interface{}{"token": "a", "refresh_token_id": "b", "api_key": "c", "api_keys": "d"},
+			map[string]interface{}{"token": Redacted, "refresh_token_id": Redacted, "api_key": Redacted, "api_keys": "d"},
+		},
+		{
+			"whole values",
+			map[string]interface{}{"password": map[string]interface{}{"old": "a", "new": "b"}},
+			map[string]interface{}{"password": Redacted},
+		},
+		{
+			"nested objects",
+			map[string]interface{}{"auth": map[string]interface{}{"user": "alice", "password": "hunter2"}},
+			map[string]interface{}{"auth": map[string]interface{}{"user": "alice", "password": Redacted}},
+		},
+		{
+			"objects in arrays",
+			map[string]interface{}{"accounts": []interface{}{
+				map[string]interface{}{"user": "alice", "api_key": "a"},
+				"bob",
+			}},
+			map[string]interface{}{"accounts": []interface{}{
+				map[string]interface{}{"user": "alice", "api_key": Redacted},
+				"bob",
+			}},
+		},
+		{
+			"other values",
+			map[string]interface{}{"count": float64(3), "enabled": true, "note": nil},
+			map[string]interface{}{"count": float64(3), "enabled": true, "note": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := l.redactMap(tt.arguments); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: redactMap(%v) = %v, want %v", tt.name, tt.arguments, got, tt.want)
+		}
+	}
+}
+
+func TestRedactMapDoesNotModifyArguments(t *testing.T) {
+	l, _ := newTestLogger(t, "password")
+	nested := map[string]interface{}{"password": "hunter2"}
+	arguments := map[string]interface{}{"password": "hunter2", "auth": nested}
+
+	l.redactMap(arguments)
+	if arguments["password"] != "hunter2" || nested["password"] != "hunter2" {
+		t.Errorf("redactMap modified its arguments: %v", arguments)
+	}
+}
+
+func TestAuditToolCall(t *testing.T) {
+	l, file := newTestLogger(t, "*secret*")
+	token := mcp.WithAuthorizer(context.Background(), &auth.Principal{Name: "ci", Scopes: []string{"*"}})
+	oauth := mcp.WithAuthorizer(context.Background(), &auth.Principal{Name: "ci", Issuer: "https://auth.example.com", Scopes: []string{"*"}})
+
+	l.AuditToolCall(token, mcp.ToolCallRecord{
+		Time:      time.Date(2026, 10, 17, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+		Transport: "http",
+		Tool:      "vikunja_create_task",
+		Arguments: map[string]interface{}{"title": "Rotate keys", "client_secret": "s3cr3t"},
+		Duration:  1500 * time.Microsecond,
+		Outcome:   mcp.OutcomeSuccess,
+	})
+	l.AuditToolCall(oauth, mcp.ToolCallRecord{
+		Time:      time.Date(2026, 10, 17, 9, 30, 30, 0, time.UTC),
+		Transport: "http",
+		Tool:      "prometheus_query",
+		Outcome:   mcp.OutcomeSuccess,
+	})
+	l.AuditToolCall(context.Background(), mcp.ToolCallRecord{
+		Time:      time.Date(2026, 10, 17, 9, 31, 0, 0, time.UTC),
+		Transport: "stdio",
+		Tool:      "portainer_stop_container",
+		Outcome:   mcp.OutcomeRejected,
+		Error:     "denied by policy rule no-prod-restarts",
+	})
+	if err := l.Close(time.Second); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t") {
+		t.Errorf("redacted value was written: %s", data)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("%d records written, want 3:\n%s", len(lines), data)
+	}
+
+	want := []Entry{
+		{
+			Time:       time.Date(2026, 10, 17, 7, 30, 0, 0, time.UTC),
+			Transport:  "http",
+			Caller:     "token ci",
+			Tool:       "vikunja_create_task",
+			Arguments:  map[string]interface{}{"title": "Rotate keys", "client_secret": Redacted},
+			DurationMS: 1.5,
+			Outcome:    mcp.OutcomeSuccess,
+		},
+		{
+			Time:      time.Date(2026, 10, 17, 9, 30, 30, 0, time.UTC),
+			Transport: "http",
+			Caller:    "oauth https://auth.example.com ci",
+			Tool:      "prometheus_query",
+			Outcome:   mcp.OutcomeSuccess,
+		},
+		{
+			Time:      time.Date(2026, 10, 17, 9, 31, 0, 0, time.UTC),
+			Transport: "stdio",
+			Caller:    auth.LocalCaller,
+			Tool:      "portainer_stop_container",
+			Outcome:   mcp.OutcomeRejected,
+			Error:     "denied by policy rule no-prod-restarts",
+		},
+	}
+	for i, line := range lines {
+		var got Entry
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("record %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name string
+		cfg  config.AuditConfig
+	}{
+		{"no destination", config.AuditConfig{Redact: []string{"password"}}},
+		{"invalid pattern", config.AuditConfig{File: filepath.Join(t.TempDir(), "audit.log"), Redact: []string{"[password"}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := New(tt.cfg, logger); err == nil {
+			t.Errorf("%s: New succeeded, want error", tt.name)
+		}
+	}
+}
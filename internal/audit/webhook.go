@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// webhookQueueSize bounds the records waiting to be sent; records
+	// arriving while it is full are dropped
+	webhookQueueSize = 1024
+
+	// webhookTimeout bounds each POST
+	webhookTimeout = 10 * time.Second
+)
+
+// webhook sends audit records in the background, one POST per record, so
+// that a slow receiver does not delay tool calls
+type webhook struct {
+	url    string
+	token  string
+	client *http.Client
+	logger *slog.Logger
+
+	queue chan []byte
+	done  chan struct{}
+
+	// mu guards closed, so that no record is queued after close
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newWebhook(url, token string, logger *slog.Logger) *webhook {
+	w := &webhook{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: webhookTimeout},
+		logger: logger,
+		queue:  make(chan []byte, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// send queues a record, dropping it when the queue is full
+func (w *webhook) send(record []byte) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- record:
+	default:
+		w.logger.Warn("Audit webhook queue full, dropping record")
+	}
+}
+
+func (w *webhook) run() {
+	defer close(w.done)
+	for record := range w.queue {
+		if err := w.post(record); err != nil {
+			w.logger.Warn("Failed to send audit record to webhook", "error", err)
+		}
+	}
+}
+
+func (w *webhook) post(record []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(record))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// close stops queueing records and waits up to timeout for the queued
+// ones to be sent
+func (w *webhook) close(timeout time.Duration) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-time.After(timeout):
+		w.logger.Warn("Audit webhook records still queued at shutdown", "records", len(w.queue))
+	}
+}
@@ -0,0 +1,263 @@
+// Package auth authenticates HTTP API tokens and checks their scopes.
+// Tokens are either configured (Store) or JWT access tokens issued by an
+// OAuth authorization server (OAuthVerifier).
+//
+// A scope is service:access, where service is a backend such as portainer
+// or prometheus and access is read or write. Write includes read, and *
+// matches any service or access: vikunja:* allows every Vikunja tool,
+// *:read every read-only tool and resource, and * everything.
+//
+// Read-only tools need read access to their service, all other tools need
+// write access. Resources need read access to the service named by their
+// URI scheme (portainer://...).
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// Access levels of a scope
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are not in the store
+	ErrInvalidToken = errors.New("invalid API token")
+
+	// ErrExpiredToken is returned for tokens past their expiry
+	ErrExpiredToken = errors.New("API token expired")
+)
+
+// Principal is the holder of a token
+type Principal struct {
+	Name    string
+	Issuer  string // OAuth issuer, empty for API tokens
+	Scopes  []string
+	Expires time.Time // zero if the token does not expire
+}
+
+// Store holds the accepted tokens by their SHA-256 digest
+type Store struct {
+	tokens []storedToken
+}
+
+type storedToken struct {
+	digest    [sha256.Size]byte
+	principal *Principal
+}
+
+// NewStore builds a store from the configured tokens and the tokens file.
+// apiToken, the legacy server.api_token, is added with scope * when set.
+func NewStore(cfg config.AuthConfig, apiToken string) (*Store, error) {
+	tokens := cfg.Tokens
+	if cfg.TokensFile != "" {
+		fileTokens, err := loadTokensFile(cfg.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(append([]config.TokenConfig(nil), tokens...), fileTokens...)
+	}
+	if apiToken != "" {
+		tokens = append(tokens, config.TokenConfig{Name: "api_token", Token: apiToken, Scopes: []string{"*"}})
+	}
+
+	s := &Store{}
+	names := make(map[string]bool, len(tokens))
+	for i, t := range tokens {
+		if t.Name == "" {
+			return nil, fmt.Errorf("token %d has no name", i)
+		}
+		if t.Name == LocalCaller {
+			return nil, fmt.Errorf("token name %q is reserved for stdio callers", t.Name)
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("token %q is defined twice", t.Name)
+		}
+		names[t.Name] = true
+
+		digest, err := tokenDigest(t)
+		if err != nil {
+			return nil, fmt.Errorf("token %q: %w", t.Name, err)
+		}
+		for _, other := range s.tokens {
+			if other.digest == digest {
+				return nil, fmt.Errorf("tokens %q and %q are the same", other.principal.Name, t.Name)
+			}
+		}
+		for _, scope := range t.Scopes {
+			if err := checkScope(scope); err != nil {
+				return nil, fmt.Errorf("token %q: %w", t.Name, err)
+			}
+		}
+		if len(t.Scopes) == 0 {
+			return nil, fmt.Errorf("token %q has no scopes", t.Name)
+		}
+
+		s.tokens = append(s.tokens, storedToken{
+			digest:    digest,
+			principal: &Principal{Name: t.Name, Scopes: t.Scopes, Expires: t.Expires},
+		})
+	}
+	return s, nil
+}
+
+// loadTokensFile reads the tokens key of a YAML file
+func loadTokensFile(path string) ([]config.TokenConfig, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("tokens file %s: %w", path, err)
+	}
+	var tokens []config.TokenConfig
+	if err := k.Unmarshal("tokens", &tokens); err != nil {
+		return nil, fmt.Errorf("tokens file %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+func tokenDigest(t config.TokenConfig) ([sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+	switch {
+	case t.Token != "" && t.SHA256 != "":
+		return digest, fmt.Errorf("set only one of token and sha256")
+	case t.Token != "":
+		return sha256.Sum256([]byte(t.Token)), nil
+	case t.SHA256 != "":
+		b, err := hex.DecodeString(t.SHA256)
+		if err != nil || len(b) != sha256.Size {
+			return digest, fmt.Errorf("sha256 must be 64 hex digits")
+		}
+		copy(digest[:], b)
+		return digest, nil
+	default:
+		return digest, fmt.Errorf("either token or sha256 is required")
+	}
+}
+
+func checkScope(scope string) error {
+	if scope == "*" {
+		return nil
+	}
+	service, access, ok := strings.Cut(scope, ":")
+	if !ok || service == "" {
+		return fmt.Errorf("invalid scope %q, want service:access", scope)
+	}
+	if access != AccessRead && access != AccessWrite && access != "*" {
+		return fmt.Errorf("invalid scope %q, access must be read, write or *", scope)
+	}
+	return nil
+}
+
+// Len returns how many tokens the store holds
+func (s *Store) Len() int {
+	return len(s.tokens)
+}
+
+// Authenticate returns the principal holding token. Every stored token is
+// compared in constant time, so the time taken does not depend on which
+// token, if any, matches.
+func (s *Store) Authenticate(token string) (*Principal, error) {
+	digest := sha256.Sum256([]byte(token))
+
+	var found *Principal
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare(digest[:], t.digest[:]) == 1 {
+			found = t.principal
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidToken
+	}
+	if !found.Expires.IsZero() && time.Now().After(found.Expires) {
+		return nil, ErrExpiredToken
+	}
+	return found, nil
+}
+
+// Allows reports whether the principal has access to service
+func (p *Principal) Allows(service, access string) bool {
+	for _, scope := range p.Scopes {
+		if scope == "*" {
+			return true
+		}
+		s, a, _ := strings.Cut(scope, ":")
+		if s != "*" && s != service {
+			continue
+		}
+		if a == "*" || a == access || (a == AccessWrite && access == AccessRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowTool implements mcp.Authorizer
+func (p *Principal) AllowTool(tool mcp.Tool) bool {
+	return p.Allows(ToolScope(tool))
+}
+
+// AllowResource implements mcp.Authorizer
+func (p *Principal) AllowResource(uri string) bool {
+	service, _, ok := strings.Cut(uri, "://")
+	return ok && p.Allows(service, AccessRead)
+}
+
+// AllowService implements mcp.Authorizer
+func (p *Principal) AllowService(service string) bool {
+	return p.Allows(service, AccessRead)
+}
+
+// Identity implements mcp.Authorizer. API tokens are identified by name,
+// OAuth subjects by issuer and subject.
+func (p *Principal) Identity() string {
+	if p.Issuer == "" {
+		return "token " + p.Name
+	}
+	return "oauth " + p.Issuer + " " + p.Name
+}
+
+// ToolScope returns the service and access a tool needs. The service is
+// the tool name's prefix, e.g. portainer for portainer_list_containers.
+func ToolScope(tool mcp.Tool) (service, access string) {
+	service, _, _ = strings.Cut(tool.Name, "_")
+	if a := tool.Annotations; a != nil && a.ReadOnlyHint != nil && *a.ReadOnlyHint {
+		return service, AccessRead
+	}
+	return service, AccessWrite
+}
+
+// PrincipalFrom returns the principal of the request in ctx, or nil for
+// requests that were not authenticated with a token
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := mcp.AuthorizerFrom(ctx).(*Principal)
+	return p
+}
+
+// LocalCaller names callers without credentials, i.e. stdio clients
+const LocalCaller = "local"
+
+// CallerIdentity returns the identity of the principal of the request in
+// ctx, or LocalCaller for requests that were not authenticated with a
+// token. Unlike names, identities of API tokens, OAuth subjects and local
+// callers never collide.
+func CallerIdentity(ctx context.Context) string {
+	if p := PrincipalFrom(ctx); p != nil {
+		return p.Identity()
+	}
+	return LocalCaller
+}
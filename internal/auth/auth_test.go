@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+func TestPrincipalAllows(t *testing.T) {
+	tests := []struct {
+		scopes  []string
+		service string
+		access  string
+		want    bool
+	}{
+		{[]string{"*"}, "portainer", AccessWrite, true},
+		{[]string{"portainer:read"}, "portainer", AccessRead, true},
+		{[]string{"portainer:read"}, "portainer", AccessWrite, false},
+		{[]string{"portainer:write"}, "portainer", AccessRead, true},
+		{[]string{"portainer:write"}, "portainer", AccessWrite, true},
+		{[]string{"portainer:*"}, "portainer", AccessWrite, true},
+		{[]string{"portainer:*"}, "grafana", AccessRead, false},
+		{[]string{"*:read"}, "grafana", AccessRead, true},
+		{[]string{"*:read"}, "grafana", AccessWrite, false},
+		{[]string{"*:write"}, "vikunja", AccessRead, true},
+		{[]string{"prometheus:read", "vikunja:write"}, "vikunja", AccessWrite, true},
+		{[]string{"prometheus:read", "vikunja:write"}, "portainer", AccessRead, false},
+		{nil, "portainer", AccessRead, false},
+	}
+
+	for _, tt := range tests {
+		p := &Principal{Name: "test", Scopes: tt.scopes}
+		if got := p.Allows(tt.service, tt.access); got != tt.want {
+			t.Errorf("%v.Allows(%q, %q) = %v, want %v", tt.scopes, tt.service, tt.access, got, tt.want)
+		}
+	}
+}
+
+func TestPrincipalAllowTool(t *testing.T) {
+	p := &Principal{Name: "test", Scopes: []string{"portainer:read", "vikunja:write"}}
+
+	tests := []struct {
+		tool mcp.Tool
+		want bool
+	}{
+		{mcp.Tool{Name: "portainer_list_containers", Annotations: mcp.ReadOnly("List")}, true},
+		{mcp.Tool{Name: "portainer_stop_container", Annotations: mcp.Destructive("Stop", true)}, false},
+		{mcp.Tool{Name: "portainer_unannotated"}, false},
+		{mcp.Tool{Name: "vikunja_create_task", Annotations: mcp.Additive("Create", false)}, true},
+		{mcp.Tool{Name: "grafana_list_dashboards", Annotations: mcp.ReadOnly("List")}, false},
+	}
+
+	for _, tt := range tests {
+		if got := p.AllowTool(tt.tool); got != tt.want {
+			t.Errorf("AllowTool(%s) = %v, want %v", tt.tool.Name, got, tt.want)
+		}
+	}
+}
+
+func TestPrincipalAllowResource(t *testing.T) {
+	p := &Principal{Name: "test", Scopes: []string{"silverbullet:read"}}
+
+	tests := []struct {
+		uri  string
+		want bool
+	}{
+		{"silverbullet://page/index", true},
+		{"portainer://endpoint/1/containers", false},
+		{"silverbullet", false},
+	}
+
+	for _, tt := range tests {
+		if got := p.AllowResource(tt.uri); got != tt.want {
+			t.Errorf("AllowResource(%q) = %v, want %v", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestStoreAuthenticate(t *testing.T) {
+	digest := sha256.Sum256([]byte("hashed-secret"))
+	store, err := NewStore(config.AuthConfig{
+		Tokens: []config.TokenConfig{
+			{Name: "plain", Token: "plain-secret", Scopes: []string{"prometheus:read"}},
+			{Name: "hashed", SHA256: hex.EncodeToString(digest[:]), Scopes: []string{"vikunja:*"}},
+			{Name: "expired", Token: "expired-secret", Scopes: []string{"*"}, Expires: time.Now().Add(-time.Hour)},
+			{Name: "current", Token: "current-secret", Scopes: []string{"*"}, Expires: time.Now().Add(time.Hour)},
+		},
+	}, "legacy-secret")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if got := store.Len(); got != 5 {
+		t.Fatalf("Len() = %d, want 5", got)
+	}
+
+	tests := []struct {
+		token   string
+		name    string
+		wantErr error
+	}{
+		{"plain-secret", "plain", nil},
+		{"hashed-secret", "hashed", nil},
+		{"current-secret", "current", nil},
+		{"legacy-secret", "api_token", nil},
+		{"expired-secret", "", ErrExpiredToken},
+		{"unknown-secret", "", ErrInvalidToken},
+		{"", "", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		p, err := store.Authenticate(tt.token)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Authenticate(%q) error = %v, want %v", tt.token, err, tt.wantErr)
+			continue
+		}
+		if err == nil && p.Name != tt.name {
+			t.Errorf("Authenticate(%q) = %q, want %q", tt.token, p.Name, tt.name)
+		}
+	}
+}
+
+func TestNewStoreRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []config.TokenConfig
+	}{
+		{"no name", []config.TokenConfig{{Token: "a", Scopes: []string{"*"}}}},
+		{"reserved name", []config.TokenConfig{{Name: LocalCaller, Token: "a", Scopes: []string{"*"}}}},
+		{"duplicate name", []config.TokenConfig{
+			{Name: "a", Token: "a", Scopes: []string{"*"}},
+			{Name: "a", Token: "b", Scopes: []string{"*"}},
+		}},
+		{"duplicate token", []config.TokenConfig{
+			{Name: "a", Token: "same", Scopes: []string{"*"}},
+			{Name: "b", Token: "same", Scopes: []string{"*"}},
+		}},
+		{"token and sha256", []config.TokenConfig{{Name: "a", Token: "a", SHA256: "00", Scopes: []string{"*"}}}},
+		{"no secret", []config.TokenConfig{{Name: "a", Scopes: []string{"*"}}}},
+		{"short sha256", []config.TokenConfig{{Name: "a", SHA256: "abcd", Scopes: []string{"*"}}}},
+		{"no scopes", []config.TokenConfig{{Name: "a", Token: "a"}}},
+		{"scope without access", []config.TokenConfig{{Name: "a", Token: "a", Scopes: []string{"portainer"}}}},
+		{"invalid access", []config.TokenConfig{{Name: "a", Token: "a", Scopes: []string{"portainer:admin"}}}},
+		{"scope without service", []config.TokenConfig{{Name: "a", Token: "a", Scopes: []string{":read"}}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewStore(config.AuthConfig{Tokens: tt.tokens}, ""); err == nil {
+			t.Errorf("%s: NewStore succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		want      string
+	}{
+		{"local", nil, LocalCaller},
+		{"API token", &Principal{Name: "ci-bot"}, "token ci-bot"},
+		{"OAuth subject", &Principal{Name: "ci-bot", Issuer: testIssuer}, "oauth " + testIssuer + " ci-bot"},
+		{"OAuth subject named local", &Principal{Name: LocalCaller, Issuer: testIssuer}, "oauth " + testIssuer + " local"},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.principal != nil {
+			ctx = mcp.WithAuthorizer(ctx, tt.principal)
+		}
+		if got := CallerIdentity(ctx); got != tt.want {
+			t.Errorf("%s: CallerIdentity = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultJWKSRefresh is how often signing keys are fetched again
+	defaultJWKSRefresh = time.Hour
+
+	// minJWKSRefetch limits how often a token signed by an unknown key
+	// causes the keys to be fetched again
+	minJWKSRefetch = time.Minute
+
+	// maxJWKSSize bounds a key set or authorization server metadata
+	maxJWKSSize = 1 << 20
+)
+
+// jwk is a JSON Web Key (RFC 7517). Only public signing keys are used.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes the key into the form golang-jwt verifies with
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		var check ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, check = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, check = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, check = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != size {
+			return nil, errors.New("invalid x")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil || len(y) != size {
+			return nil, errors.New("invalid y")
+		}
+		// Reject points that are not on the curve
+		if _, err := check.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, fmt.Errorf("invalid point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parseJWKS decodes a JSON Web Key Set into its signing keys by key ID.
+// Keys of unsupported types, or meant for encryption, are skipped.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no usable signing keys")
+	}
+	return keys, nil
+}
+
+// keySet caches the signing keys of an authorization server, fetched from
+// a URL or read from a file
+type keySet struct {
+	issuer  string
+	file    string
+	refresh time.Duration
+	client  *http.Client
+
+	// mu guards the fields below. Keys are fetched without holding it;
+	// loading is closed when the fetch in progress ends.
+	mu        sync.Mutex
+	url       string
+	keys      map[string]crypto.PublicKey
+	fetched   time.Time // of the keys
+	attempted time.Time // of the last fetch, successful or not
+	loadErr   error     // of the last fetch
+	loading   chan struct{}
+}
+
+// key returns the signing key kid. Keys are fetched again once they are
+// older than the refresh interval, or when kid is unknown, which usually
+// means the authorization server rotated its keys. Fetches, failed or
+// not, are at least minJWKSRefetch apart, and only one runs at a time;
+// other callers wait for its outcome. A token without a kid uses the only
+// key of a set that has one.
+func (ks *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	for {
+		ks.mu.Lock()
+		key, ok := ks.lookup(kid)
+		switch {
+		case ok && time.Since(ks.fetched) < ks.refresh:
+			ks.mu.Unlock()
+			return key, nil
+		case ok && ks.loading != nil:
+			// Keep using the key while fresh keys are fetched
+			ks.mu.Unlock()
+			return key, nil
+		case ks.loading != nil:
+			loading := ks.loading
+			ks.mu.Unlock()
+			select {
+			case <-loading:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		case time.Since(ks.attempted) < minJWKSRefetch:
+			noKeys, err := ks.keys == nil, ks.loadErr
+			ks.mu.Unlock()
+			switch {
+			case ok:
+				// Keep using the keys we have until the server is back
+				return key, nil
+			case noKeys && err != nil:
+				return nil, err
+			}
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+
+		ks.loading = make(chan struct{})
+		ks.attempted = time.Now()
+		jwksURL := ks.url
+		ks.mu.Unlock()
+
+		// The fetch is shared, so it does not end with the caller's request
+		keys, jwksURL, err := ks.load(context.WithoutCancel(ctx), jwksURL)
+
+		ks.mu.Lock()
+		ks.url, ks.loadErr = jwksURL, err
+		if err == nil {
+			ks.keys, ks.fetched = keys, time.Now()
+		}
+		close(ks.loading)
+		ks.loading = nil
+		ks.mu.Unlock()
+	}
+}
+
+// lookup finds kid among the keys. The caller must hold mu.
+func (ks *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// load fetches the keys from the file or from jwksURL, which is discovered
+// when empty. It returns the URL for the next fetch.
+func (ks *keySet) load(ctx context.Context, jwksURL string) (map[string]crypto.PublicKey, string, error) {
+	var data []byte
+	var err error
+	if ks.file != "" {
+		data, err = os.ReadFile(ks.file)
+	} else {
+		if jwksURL == "" {
+			if jwksURL, err = ks.discover(ctx); err != nil {
+				return nil, "", err
+			}
+		}
+		data, err = ks.get(ctx, jwksURL)
+	}
+	if err != nil {
+		return nil, jwksURL, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, jwksURL, err
+	}
+	return keys, jwksURL, nil
+}
+
+// discover finds the issuer's jwks_uri in its authorization server
+// metadata (RFC 8414), falling back to OpenID Connect discovery
+func (ks *keySet) discover(ctx context.Context) (string, error) {
+	u, err := url.Parse(ks.issuer)
+	if err != nil {
+		return "", fmt.Errorf("invalid issuer: %w", err)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	candidates := []string{
+		u.Scheme + "://" + u.Host + "/.well-known/oauth-authorization-server" + path,
+		u.Scheme + "://" + u.Host + path + "/.well-known/openid-configuration",
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		data, err := ks.get(ctx, candidate)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var metadata struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := json.Unmarshal(data, &metadata); err != nil || metadata.JWKSURI == "" {
+			lastErr = fmt.Errorf("%s has no jwks_uri", candidate)
+			continue
+		}
+		return metadata.JWKSURI, nil
+	}
+	return "", fmt.Errorf("failed to discover signing keys of %s: %w", ks.issuer, lastErr)
+}
+
+func (ks *keySet) get(ctx context.Context, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
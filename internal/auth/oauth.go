@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+)
+
+const (
+	// ProtectedResourcePath is where the protected resource metadata is
+	// published (RFC 9728)
+	ProtectedResourcePath = "/.well-known/oauth-protected-resource"
+
+	// defaultScopeClaim holds an access token's scopes (RFC 9068)
+	defaultScopeClaim = "scope"
+
+	// clockSkew is the leeway allowed on exp, nbf and iat
+	clockSkew = 30 * time.Second
+
+	// jwksTimeout bounds fetching the issuer's metadata or keys
+	jwksTimeout = 10 * time.Second
+)
+
+// signingMethods are the JWT algorithms accepted for access tokens
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// ProtectedResourceMetadata describes this server to OAuth clients, so
+// they can find the authorization server to get tokens from (RFC 9728)
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported"`
+}
+
+// OAuthVerifier validates JWT access tokens issued by an OAuth 2.1
+// authorization server, for which the HTTP API is a resource server
+type OAuthVerifier struct {
+	cfg    config.OAuthConfig
+	parser *jwt.Parser
+	keys   *keySet
+}
+
+// NewOAuthVerifier creates a verifier for cfg, which must set Issuer
+func NewOAuthVerifier(cfg config.OAuthConfig) (*OAuthVerifier, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("oauth issuer is required")
+	}
+	if u, err := url.Parse(cfg.Resource); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("oauth resource must be the URL of this server")
+	}
+	if cfg.JWKSURL != "" && cfg.JWKSFile != "" {
+		return nil, errors.New("set only one of oauth jwks_url and jwks_file")
+	}
+	for from, scopes := range cfg.ScopeMap {
+		for _, scope := range scopes {
+			if err := checkScope(scope); err != nil {
+				return nil, fmt.Errorf("oauth scope_map %q: %w", from, err)
+			}
+		}
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = cfg.Resource
+	}
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = defaultScopeClaim
+	}
+	if cfg.JWKSRefresh <= 0 {
+		cfg.JWKSRefresh = defaultJWKSRefresh
+	}
+
+	return &OAuthVerifier{
+		cfg: cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(signingMethods),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(clockSkew),
+		),
+		keys: &keySet{
+			issuer:  cfg.Issuer,
+			url:     cfg.JWKSURL,
+			file:    cfg.JWKSFile,
+			refresh: cfg.JWKSRefresh,
+			client:  &http.Client{Timeout: jwksTimeout},
+		},
+	}, nil
+}
+
+// Verify checks the signature, issuer, audience and lifetime of an access
+// token and returns its principal. The principal is named after the sub
+// claim, or client_id for tokens issued to a client itself.
+func (v *OAuthVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.key(ctx, kid)
+	})
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return nil, ErrExpiredToken
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	name, _ := claims["sub"].(string)
+	if name == "" {
+		name, _ = claims["client_id"].(string)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: no sub claim", ErrInvalidToken)
+	}
+
+	p := &Principal{Name: name, Issuer: v.cfg.Issuer, Scopes: v.toolScopes(claims[v.cfg.ScopeClaim])}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		p.Expires = exp.Time
+	}
+	return p, nil
+}
+
+// toolScopes maps the token scopes in claim, a space separated string or a
+// list, to tool scopes. Token scopes that are tool scopes apply as is;
+// others apply through the scope map and are otherwise ignored.
+func (v *OAuthVerifier) toolScopes(claim interface{}) []string {
+	var tokenScopes []string
+	switch c := claim.(type) {
+	case string:
+		tokenScopes = strings.Fields(c)
+	case []interface{}:
+		for _, s := range c {
+			if s, ok := s.(string); ok {
+				tokenScopes = append(tokenScopes, s)
+			}
+		}
+	}
+
+	var scopes []string
+	for _, s := range tokenScopes {
+		scopes = append(scopes, v.cfg.ScopeMap[s]...)
+		if checkScope(s) == nil {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// Metadata returns the protected resource metadata of this server
+func (v *OAuthVerifier) Metadata() ProtectedResourceMetadata {
+	scopes := make([]string, 0, len(v.cfg.ScopeMap))
+	for s := range v.cfg.ScopeMap {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+
+	return ProtectedResourceMetadata{
+		Resource:               v.cfg.Resource,
+		AuthorizationServers:   []string{v.cfg.Issuer},
+		ScopesSupported:        scopes,
+		BearerMethodsSupported: []string{"header"},
+	}
+}
+
+// MetadataURL returns the URL of the protected resource metadata, named in
+// the WWW-Authenticate header of 401 responses. For a resource with a path,
+// such as https://mcp.example.com/mcp, the path follows the well-known
+// prefix.
+func (v *OAuthVerifier) MetadataURL() string {
+	u, _ := url.Parse(v.cfg.Resource)
+	return u.Scheme + "://" + u.Host + ProtectedResourcePath + strings.TrimSuffix(u.Path, "/")
+}
@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testResource = "https://mcp.example.com/mcp"
+)
+
+// testKey is an ES256 signing key published under kid
+type testKey struct {
+	kid string
+	key *ecdsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return testKey{kid: kid, key: key}
+}
+
+func (k testKey) jwk() jwk {
+	return jwk{
+		Kty: "EC",
+		Kid: k.kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(k.key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(k.key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// sign issues a token with claims, which default to a valid token of
+// subject alice
+func (k testKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	full := jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testResource,
+		"sub":   "alice",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "prometheus:read",
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(full, name)
+		} else {
+			full[name] = value
+		}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, full)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func marshalJWKS(t *testing.T, keys ...testKey) []byte {
+	t.Helper()
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return data
+}
+
+func writeJWKS(t *testing.T, keys ...testKey) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, marshalJWKS(t, keys...), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestOAuthVerify(t *testing.T) {
+	signer := newTestKey(t, "current")
+	other := newTestKey(t, "current") // same kid, different key
+	unknown := newTestKey(t, "rotated")
+
+	v, err := NewOAuthVerifier(config.OAuthConfig{
+		Issuer:   testIssuer,
+		Resource: testResource,
+		JWKSFile: writeJWKS(t, signer),
+		ScopeMap: map[string][]string{"mcp:ops": {"portainer:write"}},
+	})
+	if err != nil {
+		t.Fatalf("NewOAuthVerifier: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantErr    error
+		wantName   string
+		wantScopes []string
+	}{
+		{
+			name:       "valid",
+			token:      signer.sign(t, nil),
+			wantName:   "alice",
+			wantScopes: []string{"prometheus:read"},
+		},
+		{
+			name:       "client credentials",
+			token:      signer.sign(t, jwt.MapClaims{"sub": nil, "client_id": "ci"}),
+			wantName:   "ci",
+			wantScopes: []string{"prometheus:read"},
+		},
+		{
+			name:       "mapped and unknown scopes",
+			token:      signer.sign(t, jwt.MapClaims{"scope": "openid mcp:ops grafana:read"}),
+			wantName:   "alice",
+			wantScopes: []string{"portainer:write", "grafana:read"},
+		},
+		{
+			name:       "scope list",
+			token:      signer.sign(t, jwt.MapClaims{"scope": []string{"vikunja:*"}}),
+			wantName:   "alice",
+			wantScopes: []string{"vikunja:*"},
+		},
+		{
+			name:    "bad signature",
+			token:   other.sign(t, nil),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "unknown kid",
+			token:   unknown.sign(t, nil),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signer.sign(t, jwt.MapClaims{"iss": "https://evil.example.com"}),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "wrong audience",
+			token:   signer.sign(t, jwt.MapClaims{"aud": "https://other.example.com"}),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "expired",
+			token:   signer.sign(t, jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}),
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name:       "expired within clock skew",
+			token:      signer.sign(t, jwt.MapClaims{"exp": time.Now().Add(-clockSkew / 2).Unix()}),
+			wantName:   "alice",
+			wantScopes: []string{"prometheus:read"},
+		},
+		{
+			name:    "no expiry",
+			token:   signer.sign(t, jwt.MapClaims{"exp": nil}),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "no subject",
+			token:   signer.sign(t, jwt.MapClaims{"sub": nil}),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "not a token",
+			token:   "not-a-jwt",
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		p, err := v.Verify(context.Background(), tt.token)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if p.Name != tt.wantName || p.Issuer != testIssuer || !slices.Equal(p.Scopes, tt.wantScopes) {
+			t.Errorf("%s: principal = %+v, want %s with scopes %v", tt.name, p, tt.wantName, tt.wantScopes)
+		}
+	}
+}
+
+func TestOAuthVerifyAlgNone(t *testing.T) {
+	signer := newTestKey(t, "current")
+	v, err := NewOAuthVerifier(config.OAuthConfig{
+		Issuer:   testIssuer,
+		Resource: testResource,
+		JWKSFile: writeJWKS(t, signer),
+	})
+	if err != nil {
+		t.Fatalf("NewOAuthVerifier: %v", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": testIssuer,
+		"aud": testResource,
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	if _, err := v.Verify(context.Background(), signed); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestOAuthVerifyDiscoversKeys(t *testing.T) {
+	signer := newTestKey(t, "current")
+	unknown := newTestKey(t, "rotated")
+
+	var fetches atomic.Int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"jwks_uri": srv.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(marshalJWKS(t, signer))
+	})
+
+	v, err := NewOAuthVerifier(config.OAuthConfig{Issuer: srv.URL, Resource: testResource})
+	if err != nil {
+		t.Fatalf("NewOAuthVerifier: %v", err)
+	}
+
+	token := signer.sign(t, jwt.MapClaims{"iss": srv.URL})
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(context.Background(), token); err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+	}
+
+	// A token signed by an unknown key refetches the keys at most once per
+	// minJWKSRefetch
+	rotated := unknown.sign(t, jwt.MapClaims{"iss": srv.URL})
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(context.Background(), rotated); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify: error = %v, want %v", err, ErrInvalidToken)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("keys fetched %d times, want 1", got)
+	}
+}
+
+func TestNewOAuthVerifierRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.OAuthConfig
+	}{
+		{"no issuer", config.OAuthConfig{Resource: testResource}},
+		{"no resource", config.OAuthConfig{Issuer: testIssuer}},
+		{"relative resource", config.OAuthConfig{Issuer: testIssuer, Resource: "/mcp"}},
+		{"url and file", config.OAuthConfig{Issuer: testIssuer, Resource: testResource, JWKSURL: "https://auth.example.com/jwks", JWKSFile: "jwks.json"}},
+		{"invalid mapped scope", config.OAuthConfig{Issuer: testIssuer, Resource: testResource, ScopeMap: map[string][]string{"mcp": {"portainer:admin"}}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewOAuthVerifier(tt.cfg); err == nil {
+			t.Errorf("%s: NewOAuthVerifier succeeded, want error", tt.name)
+		}
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,6 +39,12 @@ func NewClient(baseURL, token string, timeout time.Duration, skipTLSVerify bool)
 	}
 }
 
+// WrapTransport wraps the transport of the client's HTTP client, e.g. to
+// limit the requests in flight
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // Dashboard represents a Grafana dashboard
 type Dashboard struct {
 	ID        int       `json:"id"`
@@ -48,6 +57,11 @@ type Dashboard struct {
 	IsStarred bool      `json:"isStarred"`
 }
 
+// DashboardList is the structured result of listing dashboards
+type DashboardList struct {
+	Dashboards []Dashboard `json:"dashboards"`
+}
+
 // DashboardMeta represents dashboard metadata
 type DashboardMeta struct {
 	IsStarred bool   `json:"isStarred"`
@@ -198,6 +212,45 @@ func (c *Client) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
 	return rules, nil
 }
 
+// RenderPanel renders a single dashboard panel to PNG via the /render API.
+// It requires the Grafana image renderer to be installed.
+func (c *Client) RenderPanel(ctx context.Context, uid string, panelID, width, height int, from, to string) ([]byte, error) {
+	params := url.Values{}
+	params.Add("panelId", strconv.Itoa(panelID))
+	params.Add("width", strconv.Itoa(width))
+	params.Add("height", strconv.Itoa(height))
+	params.Add("from", from)
+	params.Add("to", to)
+
+	renderURL := fmt.Sprintf("%s/render/d-solo/%s/_?%s", c.baseURL, url.PathEscape(uid), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", renderURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "image/png")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Without the image renderer Grafana answers with an HTML/JSON error page
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/png") {
+		return nil, fmt.Errorf("unexpected content type %q (is the image renderer installed?)", contentType)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
 // GetHealth checks Grafana health
 func (c *Client) GetHealth(ctx context.Context) (map[string]interface{}, error) {
 	url := fmt.Sprintf("%s/api/health", c.baseURL)
@@ -0,0 +1,37 @@
+package grafana
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// RegisterCompletions registers dashboard and datasource UID completion
+func RegisterCompletions(server *mcp.Server, client *Client) {
+	server.RegisterArgumentCompletion("grafana", "uid", func(ctx context.Context, args map[string]string) ([]string, error) {
+		dashboards, err := client.ListDashboards(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dashboards: %w", err)
+		}
+
+		uids := make([]string, 0, len(dashboards))
+		for _, d := range dashboards {
+			uids = append(uids, d.UID)
+		}
+		return uids, nil
+	})
+
+	server.RegisterArgumentCompletion("grafana", "datasource_uid", func(ctx context.Context, args map[string]string) ([]string, error) {
+		datasources, err := client.ListDatasources(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list datasources: %w", err)
+		}
+
+		uids := make([]string, 0, len(datasources))
+		for _, ds := range datasources {
+			uids = append(uids, ds.UID)
+		}
+		return uids, nil
+	})
+}
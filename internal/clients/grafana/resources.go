@@ -0,0 +1,31 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// RegisterResources registers Grafana resource templates with the MCP server
+func RegisterResources(server *mcp.Server, client *Client) {
+	// Dashboard JSON
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: "grafana://dashboard/{uid}",
+		Name:        "Grafana dashboard",
+		Description: "Dashboard JSON model and metadata by dashboard UID",
+		MimeType:    "application/json",
+	}, func(ctx context.Context, uri string, params map[string]string) (string, string, error) {
+		dashboard, err := client.GetDashboard(ctx, params["uid"])
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get dashboard: %w", err)
+		}
+
+		data, err := json.MarshalIndent(dashboard, "", "  ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(data), "application/json", nil
+	})
+}
@@ -7,44 +7,70 @@ import (
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
 )
 
+// dashboardArgs identifies a dashboard
+type dashboardArgs struct {
+	UID string `json:"uid" description:"Dashboard UID" schema:"required"`
+}
+
+type createDashboardArgs struct {
+	Title     string                 `json:"title" description:"Dashboard title" schema:"required"`
+	FolderUID string                 `json:"folder_uid" description:"Folder UID (optional, default: General)"`
+	Dashboard map[string]interface{} `json:"dashboard"`
+}
+
+type renderPanelArgs struct {
+	dashboardArgs
+	PanelID int    `json:"panel_id" description:"Panel ID within the dashboard" schema:"required,minimum=1"`
+	Width   int    `json:"width" description:"Image width in pixels (default: 1000)" schema:"default=1000,minimum=100,maximum=4000"`
+	Height  int    `json:"height" description:"Image height in pixels (default: 500)" schema:"default=500,minimum=100,maximum=4000"`
+	From    string `json:"from" description:"Start of the time range (default: now-6h)" schema:"default=now-6h"`
+	To      string `json:"to" description:"End of the time range (default: now)" schema:"default=now"`
+}
+
+type createDatasourceArgs struct {
+	Name      string `json:"name" description:"Datasource name" schema:"required"`
+	Type      string `json:"type" description:"Datasource type (e.g., prometheus, loki, elasticsearch)" schema:"required"`
+	URL       string `json:"url" description:"Datasource URL" schema:"required"`
+	IsDefault bool   `json:"is_default" description:"Set as default datasource"`
+}
+
+type queryDatasourceArgs struct {
+	DatasourceUID string `json:"datasource_uid" description:"Datasource UID" schema:"required"`
+	Query         string `json:"query" description:"Query expression (e.g., PromQL for Prometheus)" schema:"required"`
+}
+
 // RegisterTools registers all Grafana tools with the MCP server
 func RegisterTools(server *mcp.Server, client *Client) {
 	// List dashboards
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_list_dashboards",
 		Description: "List all Grafana dashboards",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("List Dashboards"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
+			Properties: map[string]mcp.Property{
+				"dashboards": {
+					Type:        "array",
+					Description: "Dashboards with uid, title, tags, url and folder",
+				},
+			},
+			Required: []string{"dashboards"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		dashboards, err := client.ListDashboards(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list dashboards: %w", err)
 		}
-		return dashboards, nil
+		return DashboardList{Dashboards: dashboards}, nil
 	})
 
 	// Get dashboard
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_get_dashboard",
 		Description: "Get a specific Grafana dashboard by UID",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"uid": {
-					Type:        "string",
-					Description: "Dashboard UID",
-				},
-			},
-			Required: []string{"uid"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		uid, ok := args["uid"].(string)
-		if !ok {
-			return nil, fmt.Errorf("uid is required")
-		}
-
-		dashboard, err := client.GetDashboard(ctx, uid)
+		Annotations: mcp.ReadOnly("Get Dashboard"),
+	}, func(ctx context.Context, args dashboardArgs) (interface{}, error) {
+		dashboard, err := client.GetDashboard(ctx, args.UID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get dashboard: %w", err)
 		}
@@ -52,89 +78,125 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Create dashboard
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_create_dashboard",
 		Description: "Create a new Grafana dashboard",
+		Annotations: mcp.Additive("Create Dashboard", false),
 		InputSchema: mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
-				"title": {
-					Type:        "string",
-					Description: "Dashboard title",
-				},
-				"folder_uid": {
-					Type:        "string",
-					Description: "Folder UID (optional, default: General)",
-				},
 				"dashboard": {
 					Type:        "object",
 					Description: "Dashboard JSON definition (optional, will create basic dashboard if not provided)",
+					Properties: map[string]mcp.Property{
+						"tags": {
+							Type:        "array",
+							Description: "Dashboard tags",
+							Items:       &mcp.Property{Type: "string"},
+						},
+						"refresh": {
+							Type:        "string",
+							Description: "Auto-refresh interval (e.g., '30s', '5m')",
+						},
+						"time": {
+							Type:        "object",
+							Description: "Default time range",
+							Properties: map[string]mcp.Property{
+								"from": {Type: "string", Default: "now-6h"},
+								"to":   {Type: "string", Default: "now"},
+							},
+						},
+						"panels": {
+							Type:        "array",
+							Description: "Dashboard panels",
+							Items: &mcp.Property{
+								Type: "object",
+								Properties: map[string]mcp.Property{
+									"id":    {Type: "integer", Minimum: mcp.Float(1)},
+									"title": {Type: "string"},
+									"type": {
+										Type:        "string",
+										Description: "Panel type (e.g., 'timeseries', 'stat', 'table')",
+									},
+									"gridPos": {
+										Type: "object",
+										Properties: map[string]mcp.Property{
+											"x": {Type: "integer", Minimum: mcp.Float(0), Maximum: mcp.Float(23)},
+											"y": {Type: "integer", Minimum: mcp.Float(0)},
+											"w": {Type: "integer", Minimum: mcp.Float(1), Maximum: mcp.Float(24)},
+											"h": {Type: "integer", Minimum: mcp.Float(1)},
+										},
+									},
+									"targets": {
+										Type:        "array",
+										Description: "Panel queries",
+										Items:       &mcp.Property{Type: "object"},
+									},
+								},
+								Required: []string{"type"},
+							},
+						},
+					},
 				},
 			},
-			Required: []string{"title"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		title, ok := args["title"].(string)
-		if !ok {
-			return nil, fmt.Errorf("title is required")
-		}
-
-		folderUID := ""
-		if uid, ok := args["folder_uid"].(string); ok {
-			folderUID = uid
-		}
-
+	}, func(ctx context.Context, args createDashboardArgs) (interface{}, error) {
 		// Use provided dashboard or create a basic one
 		dashboard := map[string]interface{}{
-			"title": title,
+			"title": args.Title,
 			"tags":  []string{"mcp"},
 		}
-		if customDash, ok := args["dashboard"].(map[string]interface{}); ok {
-			dashboard = customDash
-			dashboard["title"] = title
+		if args.Dashboard != nil {
+			dashboard = args.Dashboard
+			dashboard["title"] = args.Title
 		}
 
-		result, err := client.CreateDashboard(ctx, dashboard, folderUID, false)
+		progress := mcp.Progress(ctx)
+		progress.Report(0, 2, fmt.Sprintf("Creating dashboard %q", args.Title))
+
+		result, err := client.CreateDashboard(ctx, dashboard, args.FolderUID, false)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dashboard: %w", err)
 		}
+
+		progress.Report(2, 2, "Dashboard saved")
 		return result, nil
 	})
 
 	// Delete dashboard
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_delete_dashboard",
 		Description: "Delete a Grafana dashboard by UID",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"uid": {
-					Type:        "string",
-					Description: "Dashboard UID",
-				},
-			},
-			Required: []string{"uid"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		uid, ok := args["uid"].(string)
-		if !ok {
-			return nil, fmt.Errorf("uid is required")
+		Annotations: mcp.Destructive("Delete Dashboard", true),
+	}, func(ctx context.Context, args dashboardArgs) (interface{}, error) {
+		if err := client.DeleteDashboard(ctx, args.UID); err != nil {
+			return nil, fmt.Errorf("failed to delete dashboard: %w", err)
 		}
+		return fmt.Sprintf("Dashboard %s deleted successfully", args.UID), nil
+	})
 
-		if err := client.DeleteDashboard(ctx, uid); err != nil {
-			return nil, fmt.Errorf("failed to delete dashboard: %w", err)
+	// Render panel
+	mcp.RegisterTypedTool(server, mcp.Tool{
+		Name:        "grafana_render_panel",
+		Description: "Render a Grafana dashboard panel as a PNG image",
+		Annotations: mcp.ReadOnly("Render Panel"),
+	}, func(ctx context.Context, args renderPanelArgs) (interface{}, error) {
+		png, err := client.RenderPanel(ctx, args.UID, args.PanelID, args.Width, args.Height, args.From, args.To)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render panel: %w", err)
 		}
-		return fmt.Sprintf("Dashboard %s deleted successfully", uid), nil
+		return []mcp.Content{
+			mcp.TextContent(fmt.Sprintf("Panel %d of dashboard %s (%s to %s)", args.PanelID, args.UID, args.From, args.To)),
+			mcp.ImageContent(png, "image/png"),
+		}, nil
 	})
 
 	// List datasources
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_list_datasources",
 		Description: "List all Grafana datasources",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		Annotations: mcp.ReadOnly("List Datasources"),
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		datasources, err := client.ListDatasources(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list datasources: %w", err)
@@ -143,57 +205,16 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Create datasource
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_create_datasource",
 		Description: "Create a new Grafana datasource",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"name": {
-					Type:        "string",
-					Description: "Datasource name",
-				},
-				"type": {
-					Type:        "string",
-					Description: "Datasource type (e.g., prometheus, loki, elasticsearch)",
-				},
-				"url": {
-					Type:        "string",
-					Description: "Datasource URL",
-				},
-				"is_default": {
-					Type:        "boolean",
-					Description: "Set as default datasource",
-				},
-			},
-			Required: []string{"name", "type", "url"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		name, ok := args["name"].(string)
-		if !ok {
-			return nil, fmt.Errorf("name is required")
-		}
-
-		dsType, ok := args["type"].(string)
-		if !ok {
-			return nil, fmt.Errorf("type is required")
-		}
-
-		url, ok := args["url"].(string)
-		if !ok {
-			return nil, fmt.Errorf("url is required")
-		}
-
-		isDefault := false
-		if def, ok := args["is_default"].(bool); ok {
-			isDefault = def
-		}
-
+		Annotations: mcp.Additive("Create Datasource", false),
+	}, func(ctx context.Context, args createDatasourceArgs) (interface{}, error) {
 		ds := Datasource{
-			Name:      name,
-			Type:      dsType,
-			URL:       url,
-			IsDefault: isDefault,
+			Name:      args.Name,
+			Type:      args.Type,
+			URL:       args.URL,
+			IsDefault: args.IsDefault,
 		}
 
 		result, err := client.CreateDatasource(ctx, ds)
@@ -204,35 +225,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Query datasource
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_query_datasource",
 		Description: "Query a Grafana datasource (Prometheus, Loki, etc.)",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"datasource_uid": {
-					Type:        "string",
-					Description: "Datasource UID",
-				},
-				"query": {
-					Type:        "string",
-					Description: "Query expression (e.g., PromQL for Prometheus)",
-				},
-			},
-			Required: []string{"datasource_uid", "query"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		dsUID, ok := args["datasource_uid"].(string)
-		if !ok {
-			return nil, fmt.Errorf("datasource_uid is required")
-		}
-
-		query, ok := args["query"].(string)
-		if !ok {
-			return nil, fmt.Errorf("query is required")
-		}
-
-		result, err := client.QueryDatasource(ctx, dsUID, query)
+		Annotations: mcp.ReadOnly("Query Datasource"),
+	}, func(ctx context.Context, args queryDatasourceArgs) (interface{}, error) {
+		result, err := client.QueryDatasource(ctx, args.DatasourceUID, args.Query)
 		if err != nil {
 			return nil, fmt.Errorf("failed to query datasource: %w", err)
 		}
@@ -240,13 +238,11 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// List alert rules
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_list_alert_rules",
 		Description: "List all Grafana alert rules",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		Annotations: mcp.ReadOnly("List Alert Rules"),
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		rules, err := client.ListAlertRules(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list alert rules: %w", err)
@@ -255,13 +251,11 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Get health
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "grafana_get_health",
 		Description: "Check Grafana health status",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		Annotations: mcp.ReadOnly("Grafana Health"),
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		health, err := client.GetHealth(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get health: %w", err)
@@ -0,0 +1,51 @@
+package portainer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// containerTools are the tools that act on one container
+var containerTools = []string{
+	"portainer_start_container",
+	"portainer_stop_container",
+	"portainer_restart_container",
+	"portainer_get_container_logs",
+	"portainer_inspect_container",
+}
+
+// RegisterAttributes describes the container each container tool acts on,
+// for policy rules: container.name, container.image, container.state and
+// label.<key> for each of its labels
+func RegisterAttributes(server *mcp.Server, client *Client) {
+	attributes := func(ctx context.Context, args containerArgs) (map[string]string, error) {
+		info, err := client.InspectContainer(ctx, args.EndpointID, args.ContainerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect container: %w", err)
+		}
+
+		config, _ := info["Config"].(map[string]interface{})
+		state, _ := info["State"].(map[string]interface{})
+		name, _ := info["Name"].(string)
+		image, _ := config["Image"].(string)
+		status, _ := state["Status"].(string)
+
+		attrs := map[string]string{
+			"container.name":  strings.TrimPrefix(name, "/"),
+			"container.image": image,
+			"container.state": status,
+		}
+		labels, _ := config["Labels"].(map[string]interface{})
+		for key, value := range labels {
+			attrs["label."+key] = fmt.Sprint(value)
+		}
+		return attrs, nil
+	}
+
+	for _, tool := range containerTools {
+		mcp.RegisterTypedToolAttributes(server, tool, attributes)
+	}
+}
@@ -36,6 +36,12 @@ func NewClient(baseURL, token string, timeout time.Duration, skipTLSVerify bool)
 	}
 }
 
+// WrapTransport wraps the transport of the client's HTTP client, e.g. to
+// limit the requests in flight
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // Container represents a Docker container
 type Container struct {
 	Id      string            `json:"Id"`
@@ -46,6 +52,11 @@ type Container struct {
 	Labels  map[string]string `json:"Labels"`
 }
 
+// ContainerList is the structured result of listing containers
+type ContainerList struct {
+	Containers []Container `json:"containers"`
+}
+
 // Stack represents a Docker Compose stack
 type Stack struct {
 	Id             int      `json:"Id"`
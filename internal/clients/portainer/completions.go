@@ -0,0 +1,47 @@
+package portainer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// RegisterCompletions registers container name completion for tool and
+// prompt arguments and resource template variables
+func RegisterCompletions(server *mcp.Server, client *Client) {
+	containers := func(ctx context.Context, args map[string]string) ([]string, error) {
+		// Tools and prompts name the endpoint endpoint_id, resource templates id
+		endpoint := args["endpoint_id"]
+		if endpoint == "" {
+			endpoint = args["id"]
+		}
+		endpointID := 1
+		if endpoint != "" {
+			id, err := strconv.Atoi(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid endpoint id %q", endpoint)
+			}
+			endpointID = id
+		}
+
+		list, err := client.ListContainers(ctx, endpointID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		var names []string
+		for _, c := range list {
+			for _, name := range c.Names {
+				names = append(names, strings.TrimPrefix(name, "/"))
+			}
+		}
+		return names, nil
+	}
+
+	server.RegisterArgumentCompletion("portainer", "container_id", containers)
+	server.RegisterCompletion(mcp.ResourceRef(logsTemplate), "cid", containers)
+	server.RegisterCompletion(mcp.ResourceRef(containerTemplate), "cid", containers)
+}
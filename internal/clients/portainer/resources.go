@@ -0,0 +1,86 @@
+package portainer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// Container resource templates
+const (
+	logsTemplate      = "portainer://endpoint/{id}/container/{cid}/logs{?tail}"
+	containerTemplate = "portainer://endpoint/{id}/container/{cid}"
+)
+
+// RegisterResources registers Portainer resource templates with the MCP server
+func RegisterResources(server *mcp.Server, client *Client) {
+	// Container logs, as attached by portainer_get_container_logs
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: logsTemplate,
+		Name:        "Container logs",
+		Description: "Recent log lines of a Docker container (tail defaults to 100)",
+		MimeType:    "text/plain",
+	}, func(ctx context.Context, uri string, params map[string]string) (string, string, error) {
+		endpointID, err := strconv.Atoi(params["id"])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid endpoint id %q", params["id"])
+		}
+
+		tail := 100
+		if t, ok := params["tail"]; ok {
+			if tail, err = strconv.Atoi(t); err != nil || tail < 1 {
+				return "", "", fmt.Errorf("invalid tail %q", t)
+			}
+		}
+
+		logs, err := client.GetContainerLogs(ctx, endpointID, params["cid"], tail)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get logs: %w", err)
+		}
+		return logs, "text/plain", nil
+	})
+
+	// Container details
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: containerTemplate,
+		Name:        "Container",
+		Description: "Docker inspect output of a container, including its state",
+		MimeType:    "application/json",
+	}, func(ctx context.Context, uri string, params map[string]string) (string, string, error) {
+		endpointID, err := strconv.Atoi(params["id"])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid endpoint id %q", params["id"])
+		}
+
+		info, err := client.InspectContainer(ctx, endpointID, params["cid"])
+		if err != nil {
+			return "", "", fmt.Errorf("failed to inspect container: %w", err)
+		}
+
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(data), "application/json", nil
+	})
+
+	// Notify subscribers when a container starts, stops, restarts or changes health
+	server.RegisterResourceWatcher(containerTemplate, func(ctx context.Context, uri string, params map[string]string) (string, error) {
+		endpointID, err := strconv.Atoi(params["id"])
+		if err != nil {
+			return "", fmt.Errorf("invalid endpoint id %q", params["id"])
+		}
+
+		info, err := client.InspectContainer(ctx, endpointID, params["cid"])
+		if err != nil {
+			return "", err
+		}
+
+		state, _ := info["State"].(map[string]interface{})
+		health, _ := state["Health"].(map[string]interface{})
+		return fmt.Sprintf("%v/%v/%v", state["Status"], state["StartedAt"], health["Status"]), nil
+	})
+}
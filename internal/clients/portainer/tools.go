@@ -3,202 +3,161 @@ package portainer
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
 )
 
+// endpointArgs selects the Portainer endpoint a tool operates on
+type endpointArgs struct {
+	EndpointID int `json:"endpoint_id" description:"Portainer endpoint ID (default: 1)" schema:"default=1,minimum=1"`
+}
+
+// containerArgs identifies a container on an endpoint
+type containerArgs struct {
+	endpointArgs
+	ContainerID string `json:"container_id" description:"Container ID or name" schema:"required"`
+}
+
+type containerLogsArgs struct {
+	containerArgs
+	Tail      int  `json:"tail" description:"Number of log lines to retrieve (default: 100)" schema:"default=100,minimum=1,maximum=100000"`
+	Summarize bool `json:"summarize" description:"Return a summary of the logs written by the client's LLM instead of the logs (requires client sampling support)"`
+}
+
+const (
+	// maxSummarizedLogBytes is how much of the end of the logs is sent
+	// to the client for summarizing
+	maxSummarizedLogBytes = 200 << 10
+
+	// logSummaryMaxTokens bounds the length of a log summary
+	logSummaryMaxTokens = 1024
+)
+
+type stackArgs struct {
+	StackID int `json:"stack_id" description:"Stack ID" schema:"required,minimum=1"`
+}
+
 // RegisterTools registers all Portainer tools with the MCP server
 func RegisterTools(server *mcp.Server, client *Client) {
 	// List containers
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_list_containers",
 		Description: "List all Docker containers in a Portainer environment",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("List Containers"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
-				"endpoint_id": {
-					Type:        "number",
-					Description: "Portainer endpoint ID (default: 1 for local)",
+				"containers": {
+					Type:        "array",
+					Description: "Containers with Id, Names, Image, State, Status and Labels",
 				},
 			},
+			Required: []string{"containers"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		endpointID := 1
-		if id, ok := args["endpoint_id"].(float64); ok {
-			endpointID = int(id)
-		}
-
-		containers, err := client.ListContainers(ctx, endpointID)
+	}, func(ctx context.Context, args endpointArgs) (interface{}, error) {
+		containers, err := client.ListContainers(ctx, args.EndpointID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list containers: %w", err)
 		}
 
-		return containers, nil
+		return ContainerList{Containers: containers}, nil
 	})
 
 	// Start container
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_start_container",
 		Description: "Start a Docker container",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"endpoint_id": {
-					Type:        "number",
-					Description: "Portainer endpoint ID (default: 1)",
-				},
-				"container_id": {
-					Type:        "string",
-					Description: "Container ID or name",
-				},
-			},
-			Required: []string{"container_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		endpointID := 1
-		if id, ok := args["endpoint_id"].(float64); ok {
-			endpointID = int(id)
-		}
-
-		containerID, ok := args["container_id"].(string)
-		if !ok {
-			return nil, fmt.Errorf("container_id is required")
-		}
-
-		if err := client.StartContainer(ctx, endpointID, containerID); err != nil {
+		Annotations: mcp.Additive("Start Container", true),
+	}, func(ctx context.Context, args containerArgs) (interface{}, error) {
+		if err := client.StartContainer(ctx, args.EndpointID, args.ContainerID); err != nil {
 			return nil, fmt.Errorf("failed to start container: %w", err)
 		}
 
-		return fmt.Sprintf("Container %s started successfully", containerID), nil
+		return fmt.Sprintf("Container %s started successfully", args.ContainerID), nil
 	})
 
 	// Stop container
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_stop_container",
 		Description: "Stop a Docker container",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"endpoint_id": {
-					Type:        "number",
-					Description: "Portainer endpoint ID (default: 1)",
-				},
-				"container_id": {
-					Type:        "string",
-					Description: "Container ID or name",
-				},
-			},
-			Required: []string{"container_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		endpointID := 1
-		if id, ok := args["endpoint_id"].(float64); ok {
-			endpointID = int(id)
-		}
-
-		containerID, ok := args["container_id"].(string)
-		if !ok {
-			return nil, fmt.Errorf("container_id is required")
-		}
-
-		if err := client.StopContainer(ctx, endpointID, containerID); err != nil {
+		Annotations: mcp.Destructive("Stop Container", true),
+	}, func(ctx context.Context, args containerArgs) (interface{}, error) {
+		if err := client.StopContainer(ctx, args.EndpointID, args.ContainerID); err != nil {
 			return nil, fmt.Errorf("failed to stop container: %w", err)
 		}
 
-		return fmt.Sprintf("Container %s stopped successfully", containerID), nil
+		return fmt.Sprintf("Container %s stopped successfully", args.ContainerID), nil
 	})
 
 	// Restart container
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_restart_container",
 		Description: "Restart a Docker container",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"endpoint_id": {
-					Type:        "number",
-					Description: "Portainer endpoint ID (default: 1)",
-				},
-				"container_id": {
-					Type:        "string",
-					Description: "Container ID or name",
-				},
-			},
-			Required: []string{"container_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		endpointID := 1
-		if id, ok := args["endpoint_id"].(float64); ok {
-			endpointID = int(id)
-		}
-
-		containerID, ok := args["container_id"].(string)
-		if !ok {
-			return nil, fmt.Errorf("container_id is required")
-		}
-
-		if err := client.RestartContainer(ctx, endpointID, containerID); err != nil {
+		Annotations: mcp.Destructive("Restart Container", false),
+	}, func(ctx context.Context, args containerArgs) (interface{}, error) {
+		if err := client.RestartContainer(ctx, args.EndpointID, args.ContainerID); err != nil {
 			return nil, fmt.Errorf("failed to restart container: %w", err)
 		}
 
-		return fmt.Sprintf("Container %s restarted successfully", containerID), nil
+		return fmt.Sprintf("Container %s restarted successfully", args.ContainerID), nil
 	})
 
 	// Get container logs
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_get_container_logs",
 		Description: "Retrieve logs from a Docker container",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"endpoint_id": {
-					Type:        "number",
-					Description: "Portainer endpoint ID (default: 1)",
-				},
-				"container_id": {
-					Type:        "string",
-					Description: "Container ID or name",
-				},
-				"tail": {
-					Type:        "number",
-					Description: "Number of log lines to retrieve (default: 100)",
-				},
-			},
-			Required: []string{"container_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		endpointID := 1
-		if id, ok := args["endpoint_id"].(float64); ok {
-			endpointID = int(id)
-		}
+		Annotations: mcp.ReadOnly("Get Container Logs"),
+	}, func(ctx context.Context, args containerLogsArgs) (interface{}, error) {
+		progress := mcp.Progress(ctx)
+		progress.Report(0, 2, fmt.Sprintf("Fetching last %d log lines of %s", args.Tail, args.ContainerID))
 
-		containerID, ok := args["container_id"].(string)
-		if !ok {
-			return nil, fmt.Errorf("container_id is required")
+		logs, err := client.GetContainerLogs(ctx, args.EndpointID, args.ContainerID, args.Tail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get logs: %w", err)
 		}
 
-		tail := 100
-		if t, ok := args["tail"].(float64); ok {
-			tail = int(t)
+		progress.Report(1, 2, fmt.Sprintf("Received %d bytes of logs", len(logs)))
+
+		uri := fmt.Sprintf("portainer://endpoint/%d/container/%s/logs", args.EndpointID, args.ContainerID)
+		note := ""
+		if args.Summarize {
+			summary, err := summarizeLogs(ctx, args.ContainerID, logs)
+			if err == nil {
+				progress.Report(2, 2, "Logs summarized")
+				return []mcp.Content{
+					mcp.TextContent(summary),
+					mcp.ResourceLink(mcp.Resource{
+						URI:         uri,
+						Name:        fmt.Sprintf("Logs of %s", args.ContainerID),
+						Description: fmt.Sprintf("Full logs that were summarized (%d bytes)", len(logs)),
+						MimeType:    "text/plain",
+					}),
+				}, nil
+			}
+			note = fmt.Sprintf(" (could not summarize: %v)", err)
 		}
 
-		logs, err := client.GetContainerLogs(ctx, endpointID, containerID, tail)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get logs: %w", err)
-		}
+		progress.Report(2, 2, "Logs attached")
 
-		return logs, nil
+		// Attach the logs as a resource rather than one large text block
+		return []mcp.Content{
+			mcp.TextContent(fmt.Sprintf("Last %d log lines of container %s (%d bytes) attached%s", args.Tail, args.ContainerID, len(logs), note)),
+			mcp.EmbeddedResource(mcp.ResourceContents{
+				URI:      uri,
+				MimeType: "text/plain",
+				Text:     logs,
+			}),
+		}, nil
 	})
 
 	// List stacks
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_list_stacks",
 		Description: "List all Docker Compose stacks",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		Annotations: mcp.ReadOnly("List Stacks"),
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		stacks, err := client.ListStacks(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list stacks: %w", err)
@@ -208,35 +167,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Get stack details
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_get_stack",
 		Description: "Get details of a specific Docker Compose stack",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"stack_id": {
-					Type:        "number",
-					Description: "Stack ID",
-				},
-			},
-			Required: []string{"stack_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		var stackID int
-		switch v := args["stack_id"].(type) {
-		case float64:
-			stackID = int(v)
-		case string:
-			id, err := strconv.Atoi(v)
-			if err != nil {
-				return nil, fmt.Errorf("invalid stack_id: %w", err)
-			}
-			stackID = id
-		default:
-			return nil, fmt.Errorf("stack_id is required")
-		}
-
-		stack, err := client.GetStack(ctx, stackID)
+		Annotations: mcp.ReadOnly("Get Stack"),
+	}, func(ctx context.Context, args stackArgs) (interface{}, error) {
+		stack, err := client.GetStack(ctx, args.StackID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get stack: %w", err)
 		}
@@ -245,35 +181,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Inspect container
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "portainer_inspect_container",
 		Description: "Get detailed information about a container",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"endpoint_id": {
-					Type:        "number",
-					Description: "Portainer endpoint ID (default: 1)",
-				},
-				"container_id": {
-					Type:        "string",
-					Description: "Container ID or name",
-				},
-			},
-			Required: []string{"container_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		endpointID := 1
-		if id, ok := args["endpoint_id"].(float64); ok {
-			endpointID = int(id)
-		}
-
-		containerID, ok := args["container_id"].(string)
-		if !ok {
-			return nil, fmt.Errorf("container_id is required")
-		}
-
-		info, err := client.InspectContainer(ctx, endpointID, containerID)
+		Annotations: mcp.ReadOnly("Inspect Container"),
+	}, func(ctx context.Context, args containerArgs) (interface{}, error) {
+		info, err := client.InspectContainer(ctx, args.EndpointID, args.ContainerID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to inspect container: %w", err)
 		}
@@ -281,3 +194,45 @@ func RegisterTools(server *mcp.Server, client *Client) {
 		return info, nil
 	})
 }
+
+// tailLogs returns the end of logs, at most limit bytes of it. The cut is made
+// after a newline so the first line is whole, or failing that at the start
+// of a UTF-8 character.
+func tailLogs(logs string, limit int) string {
+	if len(logs) <= limit {
+		return logs
+	}
+	cut := len(logs) - limit
+	if i := strings.IndexByte(logs[cut-1:], '\n'); i >= 0 && cut+i < len(logs) {
+		return logs[cut+i:]
+	}
+	for cut < len(logs) && !utf8.RuneStart(logs[cut]) {
+		cut++
+	}
+	return logs[cut:]
+}
+
+// summarizeLogs has the client's LLM summarize container logs
+func summarizeLogs(ctx context.Context, containerID, logs string) (string, error) {
+	logs = tailLogs(logs, maxSummarizedLogBytes)
+
+	result, err := mcp.CreateMessage(ctx, mcp.CreateMessageRequest{
+		SystemPrompt: "You summarize container logs for an operator. Be concise and factual.",
+		Messages: []mcp.SamplingMessage{{
+			Role: "user",
+			Content: mcp.TextContent(fmt.Sprintf(
+				"Summarize these logs of container %s. List errors and warnings with their timestamps and counts, "+
+					"restarts or crashes, and anything else unusual. Say so if nothing looks wrong.\n\n%s",
+				containerID, logs)),
+		}},
+		ModelPreferences: &mcp.ModelPreferences{SpeedPriority: 0.8, CostPriority: 0.5},
+		MaxTokens:        logSummaryMaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.Text() == "" {
+		return "", fmt.Errorf("client returned %s content instead of text", result.Content.Type)
+	}
+	return result.Text(), nil
+}
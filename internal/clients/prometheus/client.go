@@ -34,6 +34,12 @@ func NewClient(baseURL string, timeout time.Duration, skipTLSVerify bool) *Clien
 	}
 }
 
+// WrapTransport wraps the transport of the client's HTTP client, e.g. to
+// limit the requests in flight
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // QueryResult represents a Prometheus query result
 type QueryResult struct {
 	Status string    `json:"status"`
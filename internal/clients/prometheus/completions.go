@@ -0,0 +1,32 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// RegisterCompletions registers completion of label names, metric names
+// and alert names
+func RegisterCompletions(server *mcp.Server, client *Client) {
+	labelValues := func(label string) mcp.CompletionHandler {
+		return func(ctx context.Context, args map[string]string) ([]string, error) {
+			values, err := client.LabelValues(ctx, label)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get label values: %w", err)
+			}
+			return values, nil
+		}
+	}
+
+	server.RegisterCompletion(mcp.ToolRef("prometheus_list_label_values"), "label", func(ctx context.Context, args map[string]string) ([]string, error) {
+		names, err := client.LabelNames(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get label names: %w", err)
+		}
+		return names, nil
+	})
+	server.RegisterArgumentCompletion("prometheus", "metric", labelValues("__name__"))
+	server.RegisterArgumentCompletion("prometheus", "alert_name", labelValues("alertname"))
+}
@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+const targetsURI = "prometheus://targets"
+
+// RegisterResources registers Prometheus resources with the MCP server
+func RegisterResources(server *mcp.Server, client *Client) {
+	// Scrape targets
+	server.RegisterResource(mcp.Resource{
+		URI:         targetsURI,
+		Name:        "Prometheus scrape targets",
+		Description: "Active and dropped scrape targets with their health",
+		MimeType:    "application/json",
+	}, func(ctx context.Context, uri string) (string, string, error) {
+		targets, err := client.Targets(ctx)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get targets: %w", err)
+		}
+
+		data, err := json.MarshalIndent(targets, "", "  ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(data), "application/json", nil
+	})
+
+	// Notify subscribers when a target appears, disappears or changes health
+	server.RegisterResourceWatcher(targetsURI, func(ctx context.Context, uri string, params map[string]string) (string, error) {
+		targets, err := client.Targets(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		health := make([]string, 0, len(targets.Data.ActiveTargets))
+		for _, target := range targets.Data.ActiveTargets {
+			health = append(health, target.ScrapePool+" "+target.ScrapeURL+" "+target.Health)
+		}
+		sort.Strings(health)
+		return strings.Join(health, "\n"), nil
+	})
+}
@@ -8,29 +8,53 @@ import (
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
 )
 
+type queryArgs struct {
+	Query string `json:"query" description:"PromQL query expression" schema:"required"`
+}
+
+type queryRangeArgs struct {
+	queryArgs
+	Start string `json:"start" description:"Start time (RFC3339 format or relative like '1h' ago)" schema:"required"`
+	End   string `json:"end" description:"End time (RFC3339 format or 'now', default: now)" schema:"default=now"`
+	Step  string `json:"step" description:"Query resolution step (e.g., '15s', '1m', default: 1m)" schema:"default=1m"`
+}
+
+type labelValuesArgs struct {
+	Label string `json:"label" description:"Label name (e.g., 'job', 'instance', '__name__')" schema:"required"`
+}
+
+type findSeriesArgs struct {
+	Match    []string `json:"match" description:"Series selectors (e.g., 'up{job=\"prometheus\"}'); a single selector is accepted" schema:"required"`
+	Lookback string   `json:"lookback" description:"How far back to look (e.g., '1h', '24h', default: 1h)" schema:"default=1h,pattern=^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"`
+}
+
+type metadataArgs struct {
+	Metric string `json:"metric" description:"Metric name (optional, returns all if not specified)"`
+}
+
 // RegisterTools registers all Prometheus tools with the MCP server
 func RegisterTools(server *mcp.Server, client *Client) {
 	// Query instant
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_query",
 		Description: "Execute an instant Prometheus query",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("Prometheus Query"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
-				"query": {
+				"status": {
 					Type:        "string",
-					Description: "PromQL query expression",
+					Description: "Query status reported by Prometheus",
+				},
+				"data": {
+					Type:        "object",
+					Description: "Query data with resultType and the result series",
 				},
 			},
-			Required: []string{"query"},
+			Required: []string{"status", "data"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		query, ok := args["query"].(string)
-		if !ok {
-			return nil, fmt.Errorf("query is required")
-		}
-
-		result, err := client.Query(ctx, query, nil)
+	}, func(ctx context.Context, args queryArgs) (interface{}, error) {
+		result, err := client.Query(ctx, args.Query, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute query: %w", err)
 		}
@@ -38,78 +62,59 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Query range
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_query_range",
 		Description: "Execute a Prometheus range query over a time period",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("Prometheus Range Query"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
-				"query": {
-					Type:        "string",
-					Description: "PromQL query expression",
-				},
-				"start": {
-					Type:        "string",
-					Description: "Start time (RFC3339 format or relative like '1h' ago)",
-				},
-				"end": {
+				"status": {
 					Type:        "string",
-					Description: "End time (RFC3339 format or 'now', default: now)",
+					Description: "Query status reported by Prometheus",
 				},
-				"step": {
-					Type:        "string",
-					Description: "Query resolution step (e.g., '15s', '1m', default: 1m)",
+				"data": {
+					Type:        "object",
+					Description: "Query data with resultType and the result series",
 				},
 			},
-			Required: []string{"query", "start"},
+			Required: []string{"status", "data"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		query, ok := args["query"].(string)
-		if !ok {
-			return nil, fmt.Errorf("query is required")
-		}
-
-		startStr, ok := args["start"].(string)
-		if !ok {
-			return nil, fmt.Errorf("start is required")
-		}
-
+	}, func(ctx context.Context, args queryRangeArgs) (interface{}, error) {
 		// Parse start time
-		start, err := parseTimeOrRelative(startStr)
+		start, err := parseTimeOrRelative(args.Start)
 		if err != nil {
 			return nil, fmt.Errorf("invalid start time: %w", err)
 		}
 
 		// Parse end time (default: now)
 		end := time.Now()
-		if endStr, ok := args["end"].(string); ok && endStr != "now" {
-			end, err = parseTimeOrRelative(endStr)
+		if args.End != "now" {
+			end, err = parseTimeOrRelative(args.End)
 			if err != nil {
 				return nil, fmt.Errorf("invalid end time: %w", err)
 			}
 		}
 
-		// Step (default: 1m)
-		step := "1m"
-		if s, ok := args["step"].(string); ok {
-			step = s
-		}
+		progress := mcp.Progress(ctx)
+		progress.Report(0, 2, fmt.Sprintf("Querying %s to %s (step %s)",
+			start.Format(time.RFC3339), end.Format(time.RFC3339), args.Step))
 
-		result, err := client.QueryRange(ctx, query, start, end, step)
+		result, err := client.QueryRange(ctx, args.Query, start, end, args.Step)
 		if err != nil {
 			return nil, fmt.Errorf("failed to execute range query: %w", err)
 		}
+
+		progress.Report(2, 2, fmt.Sprintf("Received %d series", len(result.Data.Result)))
 		return result, nil
 	})
 
 	// List label names
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_list_label_names",
 		Description: "Get all Prometheus label names",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		Annotations: mcp.ReadOnly("List Label Names"),
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		labels, err := client.LabelNames(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get label names: %w", err)
@@ -118,26 +123,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// List label values
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_list_label_values",
 		Description: "Get all values for a specific Prometheus label",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"label": {
-					Type:        "string",
-					Description: "Label name (e.g., 'job', 'instance', '__name__')",
-				},
-			},
-			Required: []string{"label"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		label, ok := args["label"].(string)
-		if !ok {
-			return nil, fmt.Errorf("label is required")
-		}
-
-		values, err := client.LabelValues(ctx, label)
+		Annotations: mcp.ReadOnly("List Label Values"),
+	}, func(ctx context.Context, args labelValuesArgs) (interface{}, error) {
+		values, err := client.LabelValues(ctx, args.Label)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get label values: %w", err)
 		}
@@ -145,36 +136,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Find series
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_find_series",
 		Description: "Find time series by label matchers",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"match": {
-					Type:        "string",
-					Description: "Series selector (e.g., 'up{job=\"prometheus\"}')",
-				},
-				"lookback": {
-					Type:        "string",
-					Description: "How far back to look (e.g., '1h', '24h', default: 1h)",
-				},
-			},
-			Required: []string{"match"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		match, ok := args["match"].(string)
-		if !ok {
-			return nil, fmt.Errorf("match is required")
-		}
-
-		// Lookback period
-		lookback := "1h"
-		if lb, ok := args["lookback"].(string); ok {
-			lookback = lb
-		}
-
-		duration, err := time.ParseDuration(lookback)
+		Annotations: mcp.ReadOnly("Find Series"),
+	}, func(ctx context.Context, args findSeriesArgs) (interface{}, error) {
+		duration, err := time.ParseDuration(args.Lookback)
 		if err != nil {
 			return nil, fmt.Errorf("invalid lookback duration: %w", err)
 		}
@@ -182,7 +149,7 @@ func RegisterTools(server *mcp.Server, client *Client) {
 		end := time.Now()
 		start := end.Add(-duration)
 
-		series, err := client.Series(ctx, []string{match}, start, end)
+		series, err := client.Series(ctx, args.Match, start, end)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find series: %w", err)
 		}
@@ -190,13 +157,11 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// List targets
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_list_targets",
 		Description: "Get all Prometheus scrape targets and their health status",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		Annotations: mcp.ReadOnly("List Scrape Targets"),
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		targets, err := client.Targets(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get targets: %w", err)
@@ -205,25 +170,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Get metadata
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "prometheus_get_metadata",
 		Description: "Get metric metadata (HELP and TYPE information)",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"metric": {
-					Type:        "string",
-					Description: "Metric name (optional, returns all if not specified)",
-				},
-			},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		metric := ""
-		if m, ok := args["metric"].(string); ok {
-			metric = m
-		}
-
-		metadata, err := client.Metadata(ctx, metric)
+		Annotations: mcp.ReadOnly("Get Metric Metadata"),
+	}, func(ctx context.Context, args metadataArgs) (interface{}, error) {
+		metadata, err := client.Metadata(ctx, args.Metric)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get metadata: %w", err)
 		}
@@ -58,6 +58,12 @@ func NewClient(baseURL, token string, timeout time.Duration, skipTLSVerify bool)
 	return client
 }
 
+// WrapTransport wraps the transport of the client's HTTP client, e.g. to
+// limit the requests in flight
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // splitFirst splits string on first occurrence of separator
 func splitFirst(s, sep string) []string {
 	idx := len(s)
@@ -83,6 +89,11 @@ type Page struct {
 	Perm         string `json:"perm"` // "ro" (read-only) or "rw" (read-write)
 }
 
+// PageList is the structured result of listing pages
+type PageList struct {
+	Pages []Page `json:"pages"`
+}
+
 // SearchResult represents a search result
 type SearchResult struct {
 	Name    string `json:"name"`
@@ -219,6 +230,11 @@ func (c *Client) SearchPages(ctx context.Context, query string) ([]SearchResult,
 	return results, nil
 }
 
+// PageURL returns the browser URL of a page
+func (c *Client) PageURL(pageName string) string {
+	return fmt.Sprintf("%s/%s", c.baseURL, url.PathEscape(pageName))
+}
+
 // doRequest performs an HTTP request with JSON response
 func (c *Client) doRequest(ctx context.Context, method, url string, body interface{}, result interface{}) error {
 	var reqBody io.Reader
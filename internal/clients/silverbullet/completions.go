@@ -0,0 +1,32 @@
+package silverbullet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// RegisterCompletions registers page name completion for tool and prompt
+// arguments and the page resource template
+func RegisterCompletions(server *mcp.Server, client *Client) {
+	pages := func(ctx context.Context, args map[string]string) ([]string, error) {
+		list, err := client.ListPages(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pages: %w", err)
+		}
+
+		// The listing includes attachments; only Markdown files are pages
+		names := make([]string, 0, len(list))
+		for _, p := range list {
+			if name, ok := strings.CutSuffix(p.Name, ".md"); ok {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+
+	server.RegisterArgumentCompletion("silverbullet", "page_name", pages)
+	server.RegisterCompletion(mcp.ResourceRef(pageTemplate), "name", pages)
+}
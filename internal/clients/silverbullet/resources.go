@@ -0,0 +1,43 @@
+package silverbullet
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// pageTemplate is the resource template of pages
+const pageTemplate = "silverbullet://page/{name}"
+
+// RegisterResources registers SilverBullet resource templates with the MCP server
+func RegisterResources(server *mcp.Server, client *Client) {
+	// Page content
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: pageTemplate,
+		Name:        "SilverBullet page",
+		Description: "Markdown content of a SilverBullet page (name without .md extension)",
+		MimeType:    "text/markdown",
+	}, func(ctx context.Context, uri string, params map[string]string) (string, string, error) {
+		content, err := client.GetPage(ctx, params["name"])
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get page: %w", err)
+		}
+		return content, "text/markdown", nil
+	})
+
+	// Notify subscribers when a page is modified or deleted
+	server.RegisterResourceWatcher(pageTemplate, func(ctx context.Context, uri string, params map[string]string) (string, error) {
+		pages, err := client.ListPages(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, page := range pages {
+			if page.Name == params["name"]+".md" || page.Name == params["name"] {
+				return strconv.FormatInt(page.LastModified, 10), nil
+			}
+		}
+		return "deleted", nil
+	})
+}
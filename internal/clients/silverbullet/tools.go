@@ -7,44 +7,52 @@ import (
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
 )
 
+// pageArgs identifies a page
+type pageArgs struct {
+	PageName string `json:"page_name" description:"Page name (without .md extension)" schema:"required"`
+}
+
+type pageContentArgs struct {
+	pageArgs
+	Content string `json:"content" description:"Page content in Markdown format" schema:"required"`
+}
+
+type searchArgs struct {
+	Query string `json:"query" description:"Search query" schema:"required"`
+}
+
 // RegisterTools registers all SilverBullet tools with the MCP server
 func RegisterTools(server *mcp.Server, client *Client) {
 	// List pages
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "silverbullet_list_pages",
 		Description: "List all SilverBullet pages/notes",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("List Pages"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
+			Properties: map[string]mcp.Property{
+				"pages": {
+					Type:        "array",
+					Description: "Pages with name, created, lastModified, size, contentType and perm",
+				},
+			},
+			Required: []string{"pages"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		pages, err := client.ListPages(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list pages: %w", err)
 		}
-		return pages, nil
+		return PageList{Pages: pages}, nil
 	})
 
 	// Get page
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "silverbullet_get_page",
 		Description: "Get content of a specific SilverBullet page",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"page_name": {
-					Type:        "string",
-					Description: "Page name (without .md extension)",
-				},
-			},
-			Required: []string{"page_name"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		pageName, ok := args["page_name"].(string)
-		if !ok {
-			return nil, fmt.Errorf("page_name is required")
-		}
-
-		content, err := client.GetPage(ctx, pageName)
+		Annotations: mcp.ReadOnly("Get Page"),
+	}, func(ctx context.Context, args pageArgs) (interface{}, error) {
+		content, err := client.GetPage(ctx, args.PageName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get page: %w", err)
 		}
@@ -52,125 +60,66 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Create page
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "silverbullet_create_page",
 		Description: "Create a new SilverBullet page/note",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"page_name": {
-					Type:        "string",
-					Description: "Page name (without .md extension)",
-				},
-				"content": {
-					Type:        "string",
-					Description: "Page content in Markdown format",
-				},
-			},
-			Required: []string{"page_name", "content"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		pageName, ok := args["page_name"].(string)
-		if !ok {
-			return nil, fmt.Errorf("page_name is required")
-		}
-
-		content, ok := args["content"].(string)
-		if !ok {
-			return nil, fmt.Errorf("content is required")
-		}
-
-		if err := client.CreatePage(ctx, pageName, content); err != nil {
+		Annotations: mcp.Destructive("Create Page", true),
+	}, func(ctx context.Context, args pageContentArgs) (interface{}, error) {
+		if err := client.CreatePage(ctx, args.PageName, args.Content); err != nil {
 			return nil, fmt.Errorf("failed to create page: %w", err)
 		}
-		return fmt.Sprintf("Page '%s' created successfully", pageName), nil
+		return []mcp.Content{
+			mcp.TextContent(fmt.Sprintf("Page '%s' created successfully", args.PageName)),
+			pageLink(client, args.PageName),
+		}, nil
 	})
 
 	// Update page
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "silverbullet_update_page",
 		Description: "Update an existing SilverBullet page",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"page_name": {
-					Type:        "string",
-					Description: "Page name (without .md extension)",
-				},
-				"content": {
-					Type:        "string",
-					Description: "New page content in Markdown format",
-				},
-			},
-			Required: []string{"page_name", "content"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		pageName, ok := args["page_name"].(string)
-		if !ok {
-			return nil, fmt.Errorf("page_name is required")
-		}
-
-		content, ok := args["content"].(string)
-		if !ok {
-			return nil, fmt.Errorf("content is required")
-		}
-
-		if err := client.UpdatePage(ctx, pageName, content); err != nil {
+		Annotations: mcp.Destructive("Update Page", true),
+	}, func(ctx context.Context, args pageContentArgs) (interface{}, error) {
+		if err := client.UpdatePage(ctx, args.PageName, args.Content); err != nil {
 			return nil, fmt.Errorf("failed to update page: %w", err)
 		}
-		return fmt.Sprintf("Page '%s' updated successfully", pageName), nil
+		return []mcp.Content{
+			mcp.TextContent(fmt.Sprintf("Page '%s' updated successfully", args.PageName)),
+			pageLink(client, args.PageName),
+		}, nil
 	})
 
 	// Delete page
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "silverbullet_delete_page",
 		Description: "Delete a SilverBullet page",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"page_name": {
-					Type:        "string",
-					Description: "Page name (without .md extension)",
-				},
-			},
-			Required: []string{"page_name"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		pageName, ok := args["page_name"].(string)
-		if !ok {
-			return nil, fmt.Errorf("page_name is required")
-		}
-
-		if err := client.DeletePage(ctx, pageName); err != nil {
+		Annotations: mcp.Destructive("Delete Page", true),
+	}, func(ctx context.Context, args pageArgs) (interface{}, error) {
+		if err := client.DeletePage(ctx, args.PageName); err != nil {
 			return nil, fmt.Errorf("failed to delete page: %w", err)
 		}
-		return fmt.Sprintf("Page '%s' deleted successfully", pageName), nil
+		return fmt.Sprintf("Page '%s' deleted successfully", args.PageName), nil
 	})
 
 	// Search pages
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "silverbullet_search_pages",
 		Description: "Search SilverBullet pages by query",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"query": {
-					Type:        "string",
-					Description: "Search query",
-				},
-			},
-			Required: []string{"query"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		query, ok := args["query"].(string)
-		if !ok {
-			return nil, fmt.Errorf("query is required")
-		}
-
-		results, err := client.SearchPages(ctx, query)
+		Annotations: mcp.ReadOnly("Search Pages"),
+	}, func(ctx context.Context, args searchArgs) (interface{}, error) {
+		results, err := client.SearchPages(ctx, args.Query)
 		if err != nil {
 			return nil, fmt.Errorf("failed to search pages: %w", err)
 		}
 		return results, nil
 	})
 }
+
+// pageLink returns a resource link to a page
+func pageLink(client *Client, pageName string) mcp.Content {
+	return mcp.ResourceLink(mcp.Resource{
+		URI:      client.PageURL(pageName),
+		Name:     pageName,
+		MimeType: "text/markdown",
+	})
+}
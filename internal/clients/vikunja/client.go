@@ -36,6 +36,12 @@ func NewClient(baseURL, token string, timeout time.Duration, skipTLSVerify bool)
 	}
 }
 
+// WrapTransport wraps the transport of the client's HTTP client, e.g. to
+// limit the requests in flight
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // Project represents a Vikunja project (list)
 type Project struct {
 	ID          int       `json:"id"`
@@ -60,6 +66,16 @@ type Task struct {
 	Labels      []Label   `json:"labels,omitempty"`
 }
 
+// ProjectList is the structured result of listing projects
+type ProjectList struct {
+	Projects []Project `json:"projects"`
+}
+
+// TaskList is the structured result of listing tasks
+type TaskList struct {
+	Tasks []Task `json:"tasks"`
+}
+
 // User represents a Vikunja user
 type User struct {
 	ID       int    `json:"id"`
@@ -0,0 +1,54 @@
+package vikunja
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// RegisterCompletions registers project and task ID completion for tool
+// and prompt arguments and resource template variables
+func RegisterCompletions(server *mcp.Server, client *Client) {
+	projects := func(ctx context.Context, args map[string]string) ([]string, error) {
+		list, err := client.ListProjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		ids := make([]string, 0, len(list))
+		for _, p := range list {
+			ids = append(ids, strconv.Itoa(p.ID))
+		}
+		return ids, nil
+	}
+
+	// Tasks can only be listed once the project is known
+	tasks := func(ctx context.Context, args map[string]string) ([]string, error) {
+		project := args["project_id"]
+		if project == "" {
+			project = args["id"]
+		}
+		projectID, err := strconv.Atoi(project)
+		if err != nil {
+			return nil, nil
+		}
+
+		list, err := client.ListTasks(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		ids := make([]string, 0, len(list))
+		for _, t := range list {
+			ids = append(ids, strconv.Itoa(t.ID))
+		}
+		return ids, nil
+	}
+
+	server.RegisterArgumentCompletion("vikunja", "project_id", projects)
+	server.RegisterArgumentCompletion("vikunja", "task_id", tasks)
+	server.RegisterCompletion(mcp.ResourceRef(tasksTemplate), "id", projects)
+	server.RegisterCompletion(mcp.ResourceRef(taskTemplate), "id", projects)
+}
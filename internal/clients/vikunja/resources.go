@@ -0,0 +1,115 @@
+package vikunja
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// Task resource templates
+const (
+	tasksTemplate = "vikunja://project/{id}/tasks"
+	taskTemplate  = "vikunja://project/{id}/task/{task_id}"
+)
+
+// RegisterResources registers Vikunja resource templates with the MCP server
+func RegisterResources(server *mcp.Server, client *Client) {
+	// Tasks of a project
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: tasksTemplate,
+		Name:        "Vikunja project tasks",
+		Description: "All tasks in a Vikunja project",
+		MimeType:    "application/json",
+	}, func(ctx context.Context, uri string, params map[string]string) (string, string, error) {
+		projectID, err := strconv.Atoi(params["id"])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid project id %q", params["id"])
+		}
+
+		tasks, err := client.ListTasks(ctx, projectID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		data, err := json.MarshalIndent(TaskList{Tasks: tasks}, "", "  ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(data), "application/json", nil
+	})
+
+	// A single task
+	server.RegisterResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: taskTemplate,
+		Name:        "Vikunja task",
+		Description: "A task in a Vikunja project",
+		MimeType:    "application/json",
+	}, func(ctx context.Context, uri string, params map[string]string) (string, string, error) {
+		projectID, taskID, err := taskIDs(params)
+		if err != nil {
+			return "", "", err
+		}
+
+		task, err := client.GetTask(ctx, projectID, taskID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get task: %w", err)
+		}
+
+		data, err := json.MarshalIndent(task, "", "  ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(data), "application/json", nil
+	})
+
+	// Notify subscribers when a task is updated
+	server.RegisterResourceWatcher(taskTemplate, func(ctx context.Context, uri string, params map[string]string) (string, error) {
+		projectID, taskID, err := taskIDs(params)
+		if err != nil {
+			return "", err
+		}
+
+		task, err := client.GetTask(ctx, projectID, taskID)
+		if err != nil {
+			return "", err
+		}
+		return task.Updated.Format(time.RFC3339Nano), nil
+	})
+
+	// Notify subscribers when any task of a project is added, removed or updated
+	server.RegisterResourceWatcher(tasksTemplate, func(ctx context.Context, uri string, params map[string]string) (string, error) {
+		projectID, err := strconv.Atoi(params["id"])
+		if err != nil {
+			return "", fmt.Errorf("invalid project id %q", params["id"])
+		}
+
+		tasks, err := client.ListTasks(ctx, projectID)
+		if err != nil {
+			return "", err
+		}
+
+		var version strings.Builder
+		for _, task := range tasks {
+			fmt.Fprintf(&version, "%d@%s;", task.ID, task.Updated.Format(time.RFC3339Nano))
+		}
+		return version.String(), nil
+	})
+}
+
+// taskIDs parses the project and task IDs of a task URI
+func taskIDs(params map[string]string) (int, int, error) {
+	projectID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid project id %q", params["id"])
+	}
+	taskID, err := strconv.Atoi(params["task_id"])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid task id %q", params["task_id"])
+	}
+	return projectID, taskID, nil
+}
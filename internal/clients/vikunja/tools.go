@@ -3,49 +3,76 @@ package vikunja
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
 )
 
+// projectArgs identifies a project
+type projectArgs struct {
+	ProjectID int `json:"project_id" description:"Project ID" schema:"required,minimum=1"`
+}
+
+// taskArgs identifies a task within a project
+type taskArgs struct {
+	projectArgs
+	TaskID int `json:"task_id" description:"Task ID" schema:"required,minimum=1"`
+}
+
+type createProjectArgs struct {
+	Title       string `json:"title" description:"Project title" schema:"required"`
+	Description string `json:"description" description:"Project description (optional)"`
+}
+
+type createTaskArgs struct {
+	ProjectID   int       `json:"project_id" description:"Project ID (optional; the user is asked to choose one if omitted)" schema:"minimum=1"`
+	Title       string    `json:"title" description:"Task title" schema:"required"`
+	Description string    `json:"description" description:"Task description (optional)"`
+	Priority    int       `json:"priority" description:"Task priority (0-5, default: 0)" schema:"default=0,minimum=0,maximum=5"`
+	DueDate     time.Time `json:"due_date" description:"Due date in RFC3339 format (optional)"`
+}
+
+type updateTaskArgs struct {
+	taskArgs
+	Title       string `json:"title" description:"New task title (optional)"`
+	Description string `json:"description" description:"New task description (optional)"`
+	Done        bool   `json:"done" description:"Mark task as done/undone (optional)"`
+	Priority    int    `json:"priority" description:"New priority (0-5, optional)" schema:"minimum=0,maximum=5"`
+}
+
 // RegisterTools registers all Vikunja tools with the MCP server
 func RegisterTools(server *mcp.Server, client *Client) {
 	// List projects
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_list_projects",
 		Description: "List all Vikunja projects (lists)",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("List Projects"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
+			Properties: map[string]mcp.Property{
+				"projects": {
+					Type:        "array",
+					Description: "Projects with id, title, description, created and updated",
+				},
+			},
+			Required: []string{"projects"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	}, func(ctx context.Context, args struct{}) (interface{}, error) {
 		projects, err := client.ListProjects(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list projects: %w", err)
 		}
-		return projects, nil
+		return ProjectList{Projects: projects}, nil
 	})
 
 	// Get project
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_get_project",
 		Description: "Get a specific Vikunja project by ID",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"project_id": {
-					Type:        "number",
-					Description: "Project ID",
-				},
-			},
-			Required: []string{"project_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		projectID, ok := args["project_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("project_id is required")
-		}
-
-		project, err := client.GetProject(ctx, int(projectID))
+		Annotations: mcp.ReadOnly("Get Project"),
+	}, func(ctx context.Context, args projectArgs) (interface{}, error) {
+		project, err := client.GetProject(ctx, args.ProjectID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get project: %w", err)
 		}
@@ -53,35 +80,12 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Create project
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_create_project",
 		Description: "Create a new Vikunja project",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"title": {
-					Type:        "string",
-					Description: "Project title",
-				},
-				"description": {
-					Type:        "string",
-					Description: "Project description (optional)",
-				},
-			},
-			Required: []string{"title"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		title, ok := args["title"].(string)
-		if !ok {
-			return nil, fmt.Errorf("title is required")
-		}
-
-		description := ""
-		if desc, ok := args["description"].(string); ok {
-			description = desc
-		}
-
-		project, err := client.CreateProject(ctx, title, description)
+		Annotations: mcp.Additive("Create Project", false),
+	}, func(ctx context.Context, args createProjectArgs) (interface{}, error) {
+		project, err := client.CreateProject(ctx, args.Title, args.Description)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create project: %w", err)
 		}
@@ -89,130 +93,113 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// List tasks
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_list_tasks",
 		Description: "List all tasks in a Vikunja project",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("List Tasks"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
-				"project_id": {
-					Type:        "number",
-					Description: "Project ID",
+				"tasks": {
+					Type:        "array",
+					Description: "Tasks with id, title, description, done, priority, due_date and labels",
 				},
 			},
-			Required: []string{"project_id"},
+			Required: []string{"tasks"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		projectID, ok := args["project_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("project_id is required")
-		}
-
-		tasks, err := client.ListTasks(ctx, int(projectID))
+	}, func(ctx context.Context, args projectArgs) (interface{}, error) {
+		tasks, err := client.ListTasks(ctx, args.ProjectID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list tasks: %w", err)
 		}
-		return tasks, nil
+		return TaskList{Tasks: tasks}, nil
 	})
 
 	// Get task
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_get_task",
 		Description: "Get a specific task by ID",
-		InputSchema: mcp.InputSchema{
+		Annotations: mcp.ReadOnly("Get Task"),
+		OutputSchema: &mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
-				"project_id": {
-					Type:        "number",
-					Description: "Project ID",
-				},
-				"task_id": {
+				"id": {
 					Type:        "number",
 					Description: "Task ID",
 				},
-			},
-			Required: []string{"project_id", "task_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		projectID, ok := args["project_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("project_id is required")
-		}
-
-		taskID, ok := args["task_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("task_id is required")
-		}
-
-		task, err := client.GetTask(ctx, int(projectID), int(taskID))
-		if err != nil {
-			return nil, fmt.Errorf("failed to get task: %w", err)
-		}
-		return task, nil
-	})
-
-	// Create task
-	server.RegisterTool(mcp.Tool{
-		Name:        "vikunja_create_task",
-		Description: "Create a new task in a Vikunja project",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"project_id": {
-					Type:        "number",
-					Description: "Project ID",
-				},
 				"title": {
 					Type:        "string",
 					Description: "Task title",
 				},
 				"description": {
 					Type:        "string",
-					Description: "Task description (optional)",
+					Description: "Task description",
+				},
+				"done": {
+					Type:        "boolean",
+					Description: "Whether the task is done",
+				},
+				"project_id": {
+					Type:        "number",
+					Description: "Project the task belongs to",
 				},
 				"priority": {
 					Type:        "number",
-					Description: "Task priority (0-5, default: 0)",
+					Description: "Task priority (0-5)",
 				},
 				"due_date": {
 					Type:        "string",
-					Description: "Due date in RFC3339 format (optional)",
+					Description: "Due date in RFC3339 format",
+				},
+				"created": {
+					Type:        "string",
+					Description: "Creation time in RFC3339 format",
+				},
+				"updated": {
+					Type:        "string",
+					Description: "Last update time in RFC3339 format",
+				},
+				"created_by": {
+					Type:        "object",
+					Description: "User who created the task",
+				},
+				"labels": {
+					Type:        "array",
+					Description: "Labels attached to the task",
 				},
 			},
-			Required: []string{"project_id", "title"},
+			Required: []string{"id", "title", "done", "project_id"},
 		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		projectID, ok := args["project_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("project_id is required")
+	}, func(ctx context.Context, args taskArgs) (interface{}, error) {
+		task, err := client.GetTask(ctx, args.ProjectID, args.TaskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
 		}
+		return task, nil
+	})
 
-		title, ok := args["title"].(string)
-		if !ok {
-			return nil, fmt.Errorf("title is required")
+	// Create task
+	mcp.RegisterTypedTool(server, mcp.Tool{
+		Name:        "vikunja_create_task",
+		Description: "Create a new task in a Vikunja project",
+		Annotations: mcp.Additive("Create Task", false),
+	}, func(ctx context.Context, args createTaskArgs) (interface{}, error) {
+		if args.ProjectID == 0 {
+			projectID, err := chooseProject(ctx, client, fmt.Sprintf("Which project should the task %q be created in?", args.Title))
+			if err != nil {
+				return nil, err
+			}
+			args.ProjectID = projectID
 		}
 
 		req := CreateTaskRequest{
-			Title: title,
-		}
-
-		if desc, ok := args["description"].(string); ok {
-			req.Description = desc
-		}
-
-		if priority, ok := args["priority"].(float64); ok {
-			req.Priority = int(priority)
-		}
-
-		if dueDate, ok := args["due_date"].(string); ok {
-			t, err := time.Parse(time.RFC3339, dueDate)
-			if err != nil {
-				return nil, fmt.Errorf("invalid due_date format: %w", err)
-			}
-			req.DueDate = t
+			Title:       args.Title,
+			Description: args.Description,
+			Priority:    args.Priority,
+			DueDate:     args.DueDate,
 		}
 
-		task, err := client.CreateTask(ctx, int(projectID), req)
+		task, err := client.CreateTask(ctx, args.ProjectID, req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create task: %w", err)
 		}
@@ -220,69 +207,19 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Update task
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_update_task",
 		Description: "Update an existing Vikunja task",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"project_id": {
-					Type:        "number",
-					Description: "Project ID",
-				},
-				"task_id": {
-					Type:        "number",
-					Description: "Task ID",
-				},
-				"title": {
-					Type:        "string",
-					Description: "New task title (optional)",
-				},
-				"description": {
-					Type:        "string",
-					Description: "New task description (optional)",
-				},
-				"done": {
-					Type:        "boolean",
-					Description: "Mark task as done/undone (optional)",
-				},
-				"priority": {
-					Type:        "number",
-					Description: "New priority (0-5, optional)",
-				},
-			},
-			Required: []string{"project_id", "task_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		projectID, ok := args["project_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("project_id is required")
-		}
-
-		taskID, ok := args["task_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("task_id is required")
-		}
-
-		req := UpdateTaskRequest{}
-
-		if title, ok := args["title"].(string); ok {
-			req.Title = title
+		Annotations: mcp.Destructive("Update Task", true),
+	}, func(ctx context.Context, args updateTaskArgs) (interface{}, error) {
+		req := UpdateTaskRequest{
+			Title:       args.Title,
+			Description: args.Description,
+			Done:        args.Done,
+			Priority:    args.Priority,
 		}
 
-		if desc, ok := args["description"].(string); ok {
-			req.Description = desc
-		}
-
-		if done, ok := args["done"].(bool); ok {
-			req.Done = done
-		}
-
-		if priority, ok := args["priority"].(float64); ok {
-			req.Priority = int(priority)
-		}
-
-		task, err := client.UpdateTask(ctx, int(projectID), int(taskID), req)
+		task, err := client.UpdateTask(ctx, args.ProjectID, args.TaskID, req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to update task: %w", err)
 		}
@@ -290,37 +227,51 @@ func RegisterTools(server *mcp.Server, client *Client) {
 	})
 
 	// Delete task
-	server.RegisterTool(mcp.Tool{
+	mcp.RegisterTypedTool(server, mcp.Tool{
 		Name:        "vikunja_delete_task",
 		Description: "Delete a Vikunja task",
-		InputSchema: mcp.InputSchema{
-			Type: "object",
-			Properties: map[string]mcp.Property{
-				"project_id": {
-					Type:        "number",
-					Description: "Project ID",
-				},
-				"task_id": {
-					Type:        "number",
-					Description: "Task ID",
-				},
-			},
-			Required: []string{"project_id", "task_id"},
-		},
-	}, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-		projectID, ok := args["project_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("project_id is required")
+		Annotations: mcp.Destructive("Delete Task", true),
+	}, func(ctx context.Context, args taskArgs) (interface{}, error) {
+		if err := client.DeleteTask(ctx, args.ProjectID, args.TaskID); err != nil {
+			return nil, fmt.Errorf("failed to delete task: %w", err)
 		}
+		return fmt.Sprintf("Task %d deleted successfully", args.TaskID), nil
+	})
+}
 
-		taskID, ok := args["task_id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("task_id is required")
-		}
+// chooseProject asks the user to pick a project, for tools called without
+// one
+func chooseProject(ctx context.Context, client *Client, message string) (int, error) {
+	if !mcp.CanElicit(ctx) {
+		return 0, fmt.Errorf("project_id is required")
+	}
 
-		if err := client.DeleteTask(ctx, int(projectID), int(taskID)); err != nil {
-			return nil, fmt.Errorf("failed to delete task: %w", err)
-		}
-		return fmt.Sprintf("Task %d deleted successfully", int(taskID)), nil
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list projects: %w", err)
+	}
+	if len(projects) == 0 {
+		return 0, fmt.Errorf("there are no projects to choose from")
+	}
+
+	choice := mcp.Property{Type: "string", Title: "Project"}
+	for _, p := range projects {
+		choice.Enum = append(choice.Enum, strconv.Itoa(p.ID))
+		choice.EnumNames = append(choice.EnumNames, p.Title)
+	}
+
+	result, err := mcp.Elicit(ctx, message, mcp.InputSchema{
+		Type:       "object",
+		Properties: map[string]mcp.Property{"project_id": choice},
+		Required:   []string{"project_id"},
 	})
+	if err != nil {
+		return 0, err
+	}
+	if result.Action != mcp.ElicitAccept {
+		return 0, mcp.ErrDeclined
+	}
+
+	id, _ := result.Content["project_id"].(string)
+	return strconv.Atoi(id)
 }
@@ -14,26 +14,55 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server       ServerConfig     `koanf:"server"`
-	Log          LogConfig        `koanf:"log"`
-	Portainer    ServiceConfig    `koanf:"portainer"`
-	Grafana      ServiceConfig    `koanf:"grafana"`
-	Prometheus   ServiceConfig    `koanf:"prometheus"`
-	SilverBullet ServiceConfig    `koanf:"silverbullet"`
-	Vikunja      ServiceConfig    `koanf:"vikunja"`
-	Timeout      TimeoutConfig    `koanf:"timeout"`
-	TLS          TLSConfig        `koanf:"tls"`
+	Server       ServerConfig  `koanf:"server"`
+	Log          LogConfig     `koanf:"log"`
+	Auth         AuthConfig    `koanf:"auth"`
+	Audit        AuditConfig   `koanf:"audit"`
+	Limits       LimitsConfig  `koanf:"limits"`
+	Portainer    ServiceConfig `koanf:"portainer"`
+	Grafana      ServiceConfig `koanf:"grafana"`
+	Prometheus   ServiceConfig `koanf:"prometheus"`
+	SilverBullet ServiceConfig `koanf:"silverbullet"`
+	Vikunja      ServiceConfig `koanf:"vikunja"`
+	Timeout      TimeoutConfig `koanf:"timeout"`
+	TLS          TLSConfig     `koanf:"tls"`
 }
 
 type ServerConfig struct {
-	Name            string `koanf:"name"`
-	Version         string `koanf:"version"`
-	ProtocolVersion string `koanf:"protocol_version"`
-	HTTPEnabled     bool   `koanf:"http_enabled"`
-	HTTPPort        int    `koanf:"http_port"`
-	APIEnabled      bool   `koanf:"api_enabled"`
-	APIPort         int    `koanf:"api_port"`
-	APIToken        string `koanf:"api_token"`
+	Name            string   `koanf:"name"`
+	Version         string   `koanf:"version"`
+	ProtocolVersion string   `koanf:"protocol_version"`
+	HTTPEnabled     bool     `koanf:"http_enabled"`
+	HTTPPort        int      `koanf:"http_port"`
+	APIEnabled      bool     `koanf:"api_enabled"`
+	APIPort         int      `koanf:"api_port"`
+	APIToken        string   `koanf:"api_token"`
+	AllowedOrigins  []string `koanf:"allowed_origins"`
+	MaxInFlight     int      `koanf:"max_in_flight"`
+
+	// PageSize is how many items each page of a list method holds
+	PageSize int `koanf:"page_size"`
+
+	// AllowDestructiveTools permits tools that stop, delete or overwrite
+	AllowDestructiveTools bool `koanf:"allow_destructive_tools"`
+
+	// ConfirmDestructiveTools has the user confirm destructive tool calls
+	// on clients that support elicitation
+	ConfirmDestructiveTools bool `koanf:"confirm_destructive_tools"`
+
+	// ClientRequestTimeout bounds the wait for a client to answer a server
+	// request such as sampling/createMessage
+	ClientRequestTimeout time.Duration `koanf:"client_request_timeout"`
+
+	// ResourcePollInterval is how often subscribed resources are polled
+	ResourcePollInterval time.Duration `koanf:"resource_poll_interval"`
+
+	// PromptsDir holds the YAML prompt templates; empty disables prompts
+	PromptsDir string `koanf:"prompts_dir"`
+
+	// PolicyFile holds the rules deciding which tool calls may run; empty
+	// lets every call run
+	PolicyFile string `koanf:"policy_file"`
 }
 
 type LogConfig struct {
@@ -41,6 +70,113 @@ type LogConfig struct {
 	Format string `koanf:"format"`
 }
 
+// AuthConfig lists the tokens accepted by the HTTP API, in addition to
+// server.api_token, which is allowed everything
+type AuthConfig struct {
+	Tokens []TokenConfig `koanf:"tokens"`
+
+	// TokensFile is a YAML file with more tokens under a tokens key, so
+	// that they can be kept out of the main configuration
+	TokensFile string `koanf:"tokens_file"`
+
+	// OAuth accepts JWT access tokens issued by an authorization server
+	OAuth OAuthConfig `koanf:"oauth"`
+}
+
+// OAuthConfig makes the HTTP transports an OAuth 2.1 resource server. It is
+// enabled by setting Issuer.
+type OAuthConfig struct {
+	// Issuer is the authorization server, matched against the iss claim
+	Issuer string `koanf:"issuer"`
+
+	// Resource is the public URL of this server, published in the
+	// protected resource metadata. It is also the default audience.
+	Resource string `koanf:"resource"`
+
+	// Audience is the aud claim access tokens must carry
+	Audience string `koanf:"audience"`
+
+	// JWKSURL overrides the signing keys URL found in the issuer's
+	// metadata; JWKSFile reads the keys from a local file instead
+	JWKSURL  string `koanf:"jwks_url"`
+	JWKSFile string `koanf:"jwks_file"`
+
+	// JWKSRefresh is how often the keys are fetched again
+	JWKSRefresh time.Duration `koanf:"jwks_refresh"`
+
+	// ScopeClaim names the claim holding the token's scopes, a space
+	// separated string or a list
+	ScopeClaim string `koanf:"scope_claim"`
+
+	// ScopeMap grants tool scopes to the token scopes it maps, e.g.
+	// mcp:read to *:read. Token scopes that are tool scopes apply as is.
+	ScopeMap map[string][]string `koanf:"scope_map"`
+}
+
+// TokenConfig is an API token. Exactly one of Token and SHA256 (the hex
+// SHA-256 digest of the token) is set.
+type TokenConfig struct {
+	Name    string    `koanf:"name"`
+	Token   string    `koanf:"token"`
+	SHA256  string    `koanf:"sha256"`
+	Expires time.Time `koanf:"expires"`
+
+	// Scopes such as prometheus:read, portainer:write or vikunja:*
+	Scopes []string `koanf:"scopes"`
+}
+
+// AuditConfig says where tool calls are recorded. Auditing is off when
+// neither File nor WebhookURL is set.
+type AuditConfig struct {
+	// File receives one JSON line per call. It is rotated when it
+	// reaches MaxSizeMB, keeping MaxBackups old files for MaxAgeDays.
+	File       string `koanf:"file"`
+	MaxSizeMB  int    `koanf:"max_size_mb"`
+	MaxBackups int    `koanf:"max_backups"`
+	MaxAgeDays int    `koanf:"max_age_days"`
+	Compress   bool   `koanf:"compress"`
+
+	// WebhookURL is sent each record as a JSON POST, with WebhookToken as
+	// bearer token when set
+	WebhookURL   string `koanf:"webhook_url"`
+	WebhookToken string `koanf:"webhook_token"`
+
+	// Redact lists argument names whose values are not recorded; * matches
+	// any run of characters and case is ignored
+	Redact []string `koanf:"redact"`
+}
+
+// LimitsConfig sets the rate limits of tool calls and the concurrency caps
+// of backend requests
+type LimitsConfig struct {
+	// PerCaller limits each caller by identity: "token <name>" for an
+	// API token, "oauth <issuer> <subject>" for an OAuth subject, or local
+	// for stdio. Callers overrides it by identity.
+	PerCaller RateConfig            `koanf:"per_caller"`
+	Callers   map[string]RateConfig `koanf:"callers"`
+
+	// PerTool limits each tool across all callers; Tools overrides it by
+	// tool name
+	PerTool RateConfig            `koanf:"per_tool"`
+	Tools   map[string]RateConfig `koanf:"tools"`
+
+	// BackendConcurrency caps the requests in flight to each backend;
+	// Backends overrides it by backend (portainer, grafana, ...). 0 means
+	// no cap.
+	BackendConcurrency int            `koanf:"backend_concurrency"`
+	Backends           map[string]int `koanf:"backends"`
+
+	// BackendWait is how long a request waits for a backend below its cap
+	BackendWait time.Duration `koanf:"backend_wait"`
+}
+
+// RateConfig is a token bucket: PerMinute calls a minute on average, with
+// bursts of up to Burst calls. A PerMinute of 0 means no limit.
+type RateConfig struct {
+	PerMinute float64 `koanf:"per_minute"`
+	Burst     int     `koanf:"burst"`
+}
+
 type ServiceConfig struct {
 	URL     string `koanf:"url"`
 	Token   string `koanf:"token"`
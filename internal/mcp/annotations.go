@@ -0,0 +1,53 @@
+package mcp
+
+// Bool returns a pointer to v, for ToolAnnotations hints
+func Bool(v bool) *bool {
+	return &v
+}
+
+// ReadOnly returns annotations for a tool that only reads from the service
+// it talks to
+func ReadOnly(title string) *ToolAnnotations {
+	return &ToolAnnotations{
+		Title:         title,
+		ReadOnlyHint:  Bool(true),
+		OpenWorldHint: Bool(false),
+	}
+}
+
+// Additive returns annotations for a tool that creates or changes state
+// without destroying or overwriting existing data
+func Additive(title string, idempotent bool) *ToolAnnotations {
+	return &ToolAnnotations{
+		Title:           title,
+		ReadOnlyHint:    Bool(false),
+		DestructiveHint: Bool(false),
+		IdempotentHint:  Bool(idempotent),
+		OpenWorldHint:   Bool(false),
+	}
+}
+
+// Destructive returns annotations for a tool that may delete, stop or
+// overwrite something
+func Destructive(title string, idempotent bool) *ToolAnnotations {
+	return &ToolAnnotations{
+		Title:           title,
+		ReadOnlyHint:    Bool(false),
+		DestructiveHint: Bool(true),
+		IdempotentHint:  Bool(idempotent),
+		OpenWorldHint:   Bool(false),
+	}
+}
+
+// IsDestructive reports whether the tool may perform destructive updates,
+// applying the MCP defaults for missing hints
+func (t Tool) IsDestructive() bool {
+	a := t.Annotations
+	if a == nil {
+		return true
+	}
+	if a.ReadOnlyHint != nil && *a.ReadOnlyHint {
+		return false
+	}
+	return a.DestructiveHint == nil || *a.DestructiveHint
+}
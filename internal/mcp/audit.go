@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Outcomes of a tool call
+const (
+	// OutcomeSuccess is a call whose tool ran and succeeded
+	OutcomeSuccess = "success"
+
+	// OutcomeError is a call whose tool failed, or that was not run
+	// because it was refused, denied by policy or not confirmed
+	OutcomeError = "error"
+
+	// OutcomeRejected is a call answered with a JSON-RPC error, e.g. for
+	// an unknown tool, invalid arguments or missing scopes
+	OutcomeRejected = "rejected"
+
+	// OutcomeCancelled is a call the client cancelled
+	OutcomeCancelled = "cancelled"
+)
+
+// ToolCallRecord describes a finished tools/call request
+type ToolCallRecord struct {
+	Time      time.Time // when the call was received
+	Transport string
+	Tool      string
+	Arguments map[string]interface{} // as sent by the client
+	Duration  time.Duration
+	Outcome   string
+	Error     string
+}
+
+// ToolCallAuditor records tool calls
+type ToolCallAuditor interface {
+	// AuditToolCall records a call. It runs before the response is sent,
+	// with the request's context.
+	AuditToolCall(ctx context.Context, record ToolCallRecord)
+}
+
+// SetToolCallAuditor sets the auditor every tools/call request is
+// recorded with, on all transports. A nil auditor records nothing.
+func (s *Server) SetToolCallAuditor(a ToolCallAuditor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolCallAuditor = a
+}
+
+// auditToolCall records a tools/call request that started at start and was
+// answered with resp
+func (s *Server) auditToolCall(ctx context.Context, sess *Session, req *JSONRPCRequest, start time.Time, resp *JSONRPCResponse) {
+	s.mu.RLock()
+	auditor := s.toolCallAuditor
+	s.mu.RUnlock()
+	if auditor == nil {
+		return
+	}
+
+	var params CallToolRequest
+	_ = decodeParams(req, &params)
+
+	record := ToolCallRecord{
+		Time:      start,
+		Transport: sess.transport.Name(),
+		Tool:      params.Name,
+		Arguments: params.Arguments,
+		Duration:  time.Since(start),
+		Outcome:   OutcomeSuccess,
+	}
+	switch {
+	case ctx.Err() != nil:
+		record.Outcome = OutcomeCancelled
+	case resp.Error != nil:
+		record.Outcome = OutcomeRejected
+		record.Error = resp.Error.Message
+		if resp.Error.Data != nil {
+			record.Error += ": " + fmt.Sprint(resp.Error.Data)
+		}
+	default:
+		if result, ok := resp.Result.(CallToolResult); ok && result.IsError {
+			record.Outcome = OutcomeError
+			record.Error = resultText(result)
+		} else if result, ok := resp.Result.(*CallToolResult); ok && result.IsError {
+			record.Outcome = OutcomeError
+			record.Error = resultText(*result)
+		}
+	}
+	auditor.AuditToolCall(ctx, record)
+}
+
+// resultText returns the first text content of a tool result
+func resultText(result CallToolResult) string {
+	for _, c := range result.Content {
+		if c.Type == "text" {
+			return c.Text
+		}
+	}
+	return ""
+}
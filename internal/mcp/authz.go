@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrForbidden is returned for resources the caller may not read
+var ErrForbidden = errors.New("forbidden")
+
+// Authorizer decides what the caller of a request may use. HTTP transports
+// attach one per request from the caller's credentials; requests without
+// one, such as those on stdio, may use everything.
+type Authorizer interface {
+	// AllowTool reports whether the caller may see and call tool
+	AllowTool(tool Tool) bool
+
+	// AllowResource reports whether the caller may see and read the
+	// resource or resource template uri
+	AllowResource(uri string) bool
+
+	// AllowService reports whether the caller may read from the backend
+	// service, e.g. portainer
+	AllowService(service string) bool
+
+	// Identity identifies the caller across requests, so that sessions
+	// can be bound to the caller that created them
+	Identity() string
+}
+
+type authorizerKey struct{}
+
+// WithAuthorizer attaches the authorizer of the request's caller to ctx
+func WithAuthorizer(ctx context.Context, a Authorizer) context.Context {
+	return context.WithValue(ctx, authorizerKey{}, a)
+}
+
+// AuthorizerFrom returns the authorizer attached to ctx, or nil
+func AuthorizerFrom(ctx context.Context) Authorizer {
+	a, _ := ctx.Value(authorizerKey{}).(Authorizer)
+	return a
+}
+
+// callerIdentity returns the identity of the caller in ctx, empty for
+// requests without an authorizer
+func callerIdentity(ctx context.Context) string {
+	if a := AuthorizerFrom(ctx); a != nil {
+		return a.Identity()
+	}
+	return ""
+}
+
+func allowTool(ctx context.Context, tool Tool) bool {
+	a := AuthorizerFrom(ctx)
+	return a == nil || a.AllowTool(tool)
+}
+
+func allowResource(ctx context.Context, uri string) bool {
+	a := AuthorizerFrom(ctx)
+	return a == nil || a.AllowResource(uri)
+}
+
+func allowService(ctx context.Context, service string) bool {
+	a := AuthorizerFrom(ctx)
+	return a == nil || service == "" || a.AllowService(service)
+}
+
+// allowPrompt reports whether the caller may read from every service whose
+// resources prompt embeds
+func allowPrompt(ctx context.Context, prompt Prompt) bool {
+	for _, service := range prompt.Services {
+		if !allowService(ctx, service) {
+			return false
+		}
+	}
+	return true
+}
+
+// visible returns the items the caller in ctx may see, keeping items
+// itself when all are visible
+func visible[T any](ctx context.Context, items []T, allow func(context.Context, T) bool) []T {
+	if AuthorizerFrom(ctx) == nil {
+		return items
+	}
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if allow(ctx, item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// IsBatch reports whether a raw JSON-RPC payload is a batch (a JSON array)
+func IsBatch(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// DispatchBatch handles a JSON-RPC 2.0 batch. The requests run in parallel,
+// bounded by the max-in-flight limit, and their responses are returned in
+// request order with notifications omitted.
+//
+// The result is nil when there is nothing to send back (all notifications),
+// a single *JSONRPCResponse when the batch itself is invalid, or a
+// []*JSONRPCResponse otherwise.
+func (s *Server) DispatchBatch(ctx context.Context, sess *Session, data []byte) interface{} {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return errorResponse(nil, ParseError, "Parse error", err.Error())
+	}
+	if len(messages) == 0 {
+		return errorResponse(nil, InvalidRequest, "Invalid Request", "empty batch")
+	}
+
+	responses := make([]*JSONRPCResponse, len(messages))
+	slots := make(chan struct{}, s.maxInFlight)
+	var wg sync.WaitGroup
+
+	for i, msg := range messages {
+		var req JSONRPCRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			responses[i] = errorResponse(nil, InvalidRequest, "Invalid Request", err.Error())
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, req *JSONRPCRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			responses[i] = s.Dispatch(ctx, sess, req)
+		}(i, &req)
+	}
+
+	wg.Wait()
+
+	// Drop notifications, keeping the remaining responses in request order
+	results := make([]*JSONRPCResponse, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil {
+			results = append(results, resp)
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDispatchBatch(t *testing.T) {
+	s := newBlockingServer()
+	s.RegisterTool(Tool{Name: "slow", InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnly("Slow")},
+		func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "done", nil
+		})
+
+	type want struct {
+		id   interface{}
+		code int // 0 for a result
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want []want // nil when nothing is returned
+	}{
+		{
+			"responses keep request order",
+			`[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}},
+			  {"jsonrpc":"2.0","id":"b","method":"ping"},
+			  {"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo"}}]`,
+			[]want{{float64(1), 0}, {"b", 0}, {float64(3), 0}},
+		},
+		{
+			"notifications are left out",
+			`[{"jsonrpc":"2.0","method":"notifications/initialized"},
+			  {"jsonrpc":"2.0","id":2,"method":"ping"},
+			  {"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9}}]`,
+			[]want{{float64(2), 0}},
+		},
+		{
+			"only notifications",
+			`[{"jsonrpc":"2.0","method":"notifications/initialized"},
+			  {"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9}}]`,
+			nil,
+		},
+		{
+			"errors per element",
+			`[1,
+			  {"jsonrpc":"2.0","id":2,"method":"ping"},
+			  {"jsonrpc":"1.0","id":3,"method":"ping"},
+			  {"jsonrpc":"2.0","id":4},
+			  {"jsonrpc":"2.0","id":5,"method":"no/such/method"}]`,
+			[]want{{nil, InvalidRequest}, {float64(2), 0}, {float64(3), InvalidRequest}, {float64(4), InvalidRequest}, {float64(5), MethodNotFound}},
+		},
+	}
+
+	for _, tt := range tests {
+		out := s.DispatchBatch(context.Background(), initializedSession(t, s), []byte(tt.body))
+		if tt.want == nil {
+			if out != nil {
+				t.Errorf("%s: got %+v, want nothing", tt.name, out)
+			}
+			continue
+		}
+
+		responses, ok := out.([]*JSONRPCResponse)
+		if !ok {
+			t.Errorf("%s: got %T, want []*JSONRPCResponse", tt.name, out)
+			continue
+		}
+		if len(responses) != len(tt.want) {
+			t.Errorf("%s: %d responses, want %d", tt.name, len(responses), len(tt.want))
+			continue
+		}
+		for i, resp := range responses {
+			code := 0
+			if resp.Error != nil {
+				code = resp.Error.Code
+			}
+			if resp.ID != tt.want[i].id || code != tt.want[i].code {
+				t.Errorf("%s: response %d has id %v and code %d, want id %v and code %d",
+					tt.name, i, resp.ID, code, tt.want[i].id, tt.want[i].code)
+			}
+		}
+	}
+}
+
+func TestDispatchBatchInvalid(t *testing.T) {
+	s := newBlockingServer()
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"empty batch", `[]`, InvalidRequest},
+		{"not JSON", `[{"jsonrpc":"2.0",`, ParseError},
+	}
+
+	for _, tt := range tests {
+		sess := NewSession(&stdioTransport{out: io.Discard})
+		resp, ok := s.DispatchBatch(context.Background(), sess, []byte(tt.body)).(*JSONRPCResponse)
+		if !ok || resp.Error == nil || resp.Error.Code != tt.code || resp.ID != nil {
+			t.Errorf("%s: got %+v, want a single error with code %d", tt.name, resp, tt.code)
+		}
+	}
+}
+
+func TestIsBatch(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{`[]`, true},
+		{" \n\t[{}]", true},
+		{`{"jsonrpc":"2.0"}`, false},
+		{``, false},
+		{`  `, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBatch([]byte(tt.data)); got != tt.want {
+			t.Errorf("IsBatch(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
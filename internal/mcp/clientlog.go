@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logLevels maps the syslog levels used by MCP to slog levels, in
+// increasing severity
+var logLevels = []struct {
+	name  string
+	level slog.Level
+}{
+	{"debug", slog.LevelDebug},
+	{"info", slog.LevelInfo},
+	{"notice", slog.LevelInfo + 2},
+	{"warning", slog.LevelWarn},
+	{"error", slog.LevelError},
+	{"critical", slog.LevelError + 4},
+	{"alert", slog.LevelError + 8},
+	{"emergency", slog.LevelError + 12},
+}
+
+// parseLogLevel returns the slog level of an MCP level name
+func parseLogLevel(name string) (slog.Level, bool) {
+	for _, l := range logLevels {
+		if l.name == name {
+			return l.level, true
+		}
+	}
+	return 0, false
+}
+
+// logLevelName returns the MCP name of a slog level, rounding down to the
+// nearest MCP level
+func logLevelName(level slog.Level) string {
+	name := logLevels[0].name
+	for _, l := range logLevels {
+		if level >= l.level {
+			name = l.name
+		}
+	}
+	return name
+}
+
+// SetLogger sets the logger for server diagnostics. Records logged with
+// the context of a client request are also sent as notifications/message
+// to that request's client, if it asked for them with logging/setLevel.
+// Other records stay on the server, so one client never sees another's
+// logs.
+func (s *Server) SetLogger(logger *slog.Logger) {
+	s.logger = slog.New(&clientLogHandler{server: s, next: logger.Handler()})
+}
+
+// Logger returns the server's logger, which forwards records to clients.
+// Installing it with slog.SetDefault forwards the application's logs too
+// when they are written with a request's context, e.g. by slog.InfoContext.
+func (s *Server) Logger() *slog.Logger {
+	return s.logger
+}
+
+func (s *Server) handleSetLevel(sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
+	var params SetLevelRequest
+	if err := decodeParams(req, &params); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	level, ok := parseLogLevel(params.Level)
+	if !ok {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", "unknown log level "+params.Level)
+	}
+	sess.setLogLevel(level)
+
+	return resultResponse(req.ID, map[string]interface{}{})
+}
+
+// logSession returns the session of the client request in ctx if it wants
+// records at level, or nil
+func logSession(ctx context.Context, level slog.Level) *Session {
+	if sess := ClientSession(ctx); sess != nil && sess.wantsLog(level) {
+		return sess
+	}
+	return nil
+}
+
+// clientLogHandler passes records to the next handler and forwards those
+// logged during a client request to its session, when they meet the
+// session's log level
+type clientLogHandler struct {
+	server *Server
+	next   slog.Handler
+
+	attrs []slog.Attr // from WithAttrs, keys qualified by group
+	group string      // dotted prefix from WithGroup
+}
+
+func (h *clientLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level) || logSession(ctx, level) != nil
+}
+
+func (h *clientLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if h.next.Enabled(ctx, r.Level) {
+		err = h.next.Handle(ctx, r)
+	}
+
+	sess := logSession(ctx, r.Level)
+	if sess == nil {
+		return err
+	}
+
+	data := map[string]interface{}{"message": r.Message}
+	for _, a := range h.attrs {
+		addLogAttr(data, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addLogAttr(data, h.group, a)
+		return true
+	})
+
+	notification := newNotification("notifications/message", LoggingMessageNotification{
+		Level:  logLevelName(r.Level),
+		Logger: h.server.serverInfo.Name,
+		Data:   data,
+	})
+	// ctx routes the notification to the stream of the request being
+	// logged about. Send failures are dropped, since logging them would be
+	// forwarded again.
+	_ = sess.transport.Send(ctx, notification)
+	return err
+}
+
+func (h *clientLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.group + a.Key
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+func (h *clientLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// addLogAttr adds an attribute to a notification's data as JSON-friendly
+// values, flattening groups into dotted keys
+func addLogAttr(data map[string]interface{}, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addLogAttr(data, prefix, ga)
+		}
+	case slog.KindDuration:
+		data[prefix+a.Key] = v.Duration().String()
+	case slog.KindTime:
+		data[prefix+a.Key] = v.Time().Format(time.RFC3339Nano)
+	default:
+		if err, ok := v.Any().(error); ok {
+			data[prefix+a.Key] = err.Error()
+		} else {
+			data[prefix+a.Key] = v.Any()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// logTransport records the log messages sent to a session
+type logTransport struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (t *logTransport) Name() string { return "test" }
+func (t *logTransport) persistent()  {}
+
+func (t *logTransport) Send(ctx context.Context, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var n struct {
+		Method string `json:"method"`
+		Params struct {
+			Data struct {
+				Message string `json:"message"`
+			} `json:"data"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	if n.Method == "notifications/message" {
+		t.mu.Lock()
+		t.messages = append(t.messages, n.Params.Data.Message)
+		t.mu.Unlock()
+	}
+	return nil
+}
+
+func (t *logTransport) received() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.messages
+}
+
+// logSessionAt opens an initialized session that asked for records at level
+func logSessionAt(t *testing.T, s *Server, level string) (*Session, *logTransport) {
+	t.Helper()
+	transport := &logTransport{}
+	sess := NewSession(transport)
+	s.beginSession(sess)
+	for _, msg := range []string{
+		initializeRequest,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"` + level + `"}}`,
+	} {
+		var req JSONRPCRequest
+		if err := json.Unmarshal([]byte(msg), &req); err != nil {
+			t.Fatalf("decode %s: %v", msg, err)
+		}
+		if resp := s.Dispatch(context.Background(), sess, &req); resp != nil && resp.Error != nil {
+			t.Fatalf("%s: %+v", req.Method, resp.Error)
+		}
+	}
+	return sess, transport
+}
+
+func TestClientLogScopedToSession(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	s.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.RegisterTool(Tool{Name: "drop", InputSchema: InputSchema{Type: "object"}},
+		func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+			return "dropped", nil
+		})
+
+	alice, aliceLog := logSessionAt(t, s, "debug")
+	bob, bobLog := logSessionAt(t, s, "warning")
+	ctx := context.Background()
+
+	// Records outside a request stay on the server
+	s.Logger().Warn("Resource poll failed")
+
+	// Records of a request go to its session only, at the session's level
+	s.Logger().InfoContext(withScope(ctx, s, alice), "About alice")
+	s.Logger().InfoContext(withScope(ctx, s, bob), "About bob")
+	s.Logger().ErrorContext(withScope(ctx, s, bob), "Failed for bob")
+
+	// The server's own records of a request are forwarded the same way
+	req := &JSONRPCRequest{JSONRPC: "2.0", ID: float64(2), Method: "tools/call", Params: json.RawMessage(`{"name":"drop"}`)}
+	s.Dispatch(ctx, alice, req)
+
+	if got, want := aliceLog.received(), []string{"About alice", "Received request", "Refused destructive tool"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("alice received %q, want %q", got, want)
+	}
+	if got, want := bobLog.received(), []string{"Failed for bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("bob received %q, want %q", got, want)
+	}
+}
+
+func TestClientLogWithoutSetLevel(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	s.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	transport := &logTransport{}
+	sess := NewSession(transport)
+	s.beginSession(sess)
+	s.Logger().ErrorContext(withScope(context.Background(), s, sess), "Failed")
+
+	if got := transport.received(); len(got) != 0 {
+		t.Errorf("received %q before logging/setLevel, want nothing", got)
+	}
+}
@@ -0,0 +1,296 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CompletionHandler returns the candidate values of an argument. arguments
+// holds the values the client has already filled in for other arguments,
+// e.g. the endpoint when completing a container. The server filters the
+// candidates by what the user has typed, so handlers return all of them.
+type CompletionHandler func(ctx context.Context, arguments map[string]string) ([]string, error)
+
+const (
+	// completionCacheTTL is how long candidates are reused, so that each
+	// keystroke does not query the backend again
+	completionCacheTTL = 30 * time.Second
+
+	// maxCompletionValues is the most values a completion may return
+	maxCompletionValues = 100
+)
+
+// completer caches the candidates of one handler per set of context
+// arguments
+type completer struct {
+	handler CompletionHandler
+
+	// service is the backend a shared completer queries; callers must be
+	// allowed to read from it. Completers of a single reference have none,
+	// since the reference itself is authorized.
+	service string
+
+	mu    sync.Mutex
+	cache map[string]cachedCompletion
+}
+
+type cachedCompletion struct {
+	values  []string
+	expires time.Time
+}
+
+// completionKey identifies the argument of a prompt, tool or resource
+// template that a completer serves
+type completionKey struct {
+	ref      CompleteReference
+	argument string
+}
+
+// PromptRef references a prompt for completion
+func PromptRef(name string) CompleteReference {
+	return CompleteReference{Type: RefPrompt, Name: name}
+}
+
+// ToolRef references a tool for completion
+func ToolRef(name string) CompleteReference {
+	return CompleteReference{Type: RefTool, Name: name}
+}
+
+// ResourceRef references a resource template for completion of its
+// variables
+func ResourceRef(uriTemplate string) CompleteReference {
+	return CompleteReference{Type: RefResource, URI: uriTemplate}
+}
+
+// RegisterCompletion registers the candidates of one argument of a prompt,
+// tool or resource template variable
+func (s *Server) RegisterCompletion(ref CompleteReference, argument string, handler CompletionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completers[completionKey{ref: ref, argument: argument}] = newCompleter("", handler)
+}
+
+// RegisterArgumentCompletion registers the candidates of every prompt
+// argument, tool argument and resource template variable named argument
+// that has no completion of its own. handler queries the backend service,
+// and only completes for callers allowed to read from it. Argument
+// completions are shared, so they stay registered when tools or resources
+// are unregistered.
+func (s *Server) RegisterArgumentCompletion(service, argument string, handler CompletionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completers[completionKey{argument: argument}] = newCompleter(service, handler)
+}
+
+// dropCompletions removes the completions registered for ref. The caller
+// must hold mu.
+func (s *Server) dropCompletions(ref CompleteReference) {
+	for key := range s.completers {
+		if key.ref == ref {
+			delete(s.completers, key)
+		}
+	}
+}
+
+func newCompleter(service string, handler CompletionHandler) *completer {
+	return &completer{
+		handler: handler,
+		service: service,
+		cache:   make(map[string]cachedCompletion),
+	}
+}
+
+func (s *Server) handleComplete(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	var params CompleteRequest
+	if err := decodeParams(req, &params); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	s.mu.RLock()
+	resp := s.checkCompleteReference(ctx, req, params.Ref)
+	c, ok := s.completers[completionKey{ref: params.Ref, argument: params.Argument.Name}]
+	if !ok && s.declaresArgument(params.Ref, params.Argument.Name) {
+		c, ok = s.completers[completionKey{argument: params.Argument.Name}]
+	}
+	s.mu.RUnlock()
+	if resp != nil {
+		return resp
+	}
+	if !ok || !allowService(ctx, c.service) {
+		return resultResponse(req.ID, CompleteResult{Completion: Completion{Values: []string{}}})
+	}
+
+	var arguments map[string]string
+	if params.Context != nil {
+		arguments = params.Context.Arguments
+	}
+	candidates, err := c.candidates(ctx, arguments)
+	if err != nil {
+		return errorResponse(req.ID, InternalError, "Internal error", err.Error())
+	}
+
+	return resultResponse(req.ID, CompleteResult{
+		Completion: filterCompletions(candidates, params.Argument.Value),
+	})
+}
+
+// checkCompleteReference returns an error response if ref does not name a
+// registered prompt, tool or resource template that the caller in ctx may
+// use. The caller must hold mu.
+func (s *Server) checkCompleteReference(ctx context.Context, req *JSONRPCRequest, ref CompleteReference) *JSONRPCResponse {
+	switch ref.Type {
+	case RefPrompt:
+		if prompt := s.findPrompt(ref.Name); prompt != nil {
+			if !allowPrompt(ctx, *prompt) {
+				return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+ref.Name)
+			}
+			return nil
+		}
+		return errorResponse(req.ID, InvalidParams, "Prompt not found", ref.Name)
+	case RefTool:
+		if tool := s.findTool(ref.Name); tool != nil {
+			if !allowTool(ctx, *tool) {
+				return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+ref.Name)
+			}
+			return nil
+		}
+		return errorResponse(req.ID, InvalidParams, "Tool not found", ref.Name)
+	case RefResource:
+		if !allowResource(ctx, ref.URI) {
+			return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+ref.URI)
+		}
+		if _, ok := s.resourceHandlers[ref.URI]; ok {
+			return nil
+		}
+		for _, t := range s.resourceTemplates {
+			if t.URITemplate == ref.URI {
+				return nil
+			}
+		}
+		return errorResponse(req.ID, InvalidParams, "Resource not found", ref.URI)
+	default:
+		return errorResponse(req.ID, InvalidParams, "Invalid params", "unknown reference type "+ref.Type)
+	}
+}
+
+// declaresArgument reports whether the prompt, tool or resource template
+// ref has an argument or variable named argument. The caller must hold mu.
+func (s *Server) declaresArgument(ref CompleteReference, argument string) bool {
+	switch ref.Type {
+	case RefPrompt:
+		if prompt := s.findPrompt(ref.Name); prompt != nil {
+			for _, arg := range prompt.Arguments {
+				if arg.Name == argument {
+					return true
+				}
+			}
+		}
+	case RefTool:
+		if tool := s.findTool(ref.Name); tool != nil {
+			_, ok := tool.InputSchema.Properties[argument]
+			return ok
+		}
+	case RefResource:
+		for _, t := range s.resourceTemplates {
+			if t.URITemplate == ref.URI {
+				return t.uriTemplate.hasVariable(argument)
+			}
+		}
+	}
+	return false
+}
+
+// findPrompt returns the prompt named name, or nil. The caller must hold
+// mu.
+func (s *Server) findPrompt(name string) *Prompt {
+	for i := range s.prompts {
+		if s.prompts[i].Name == name {
+			return &s.prompts[i]
+		}
+	}
+	return nil
+}
+
+// candidates returns the handler's values, from the cache while fresh
+func (c *completer) candidates(ctx context.Context, arguments map[string]string) ([]string, error) {
+	key := argumentsKey(arguments)
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.values, nil
+	}
+
+	values, err := c.handler(ctx, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	for k, entry := range c.cache {
+		if !now.Before(entry.expires) {
+			delete(c.cache, k)
+		}
+	}
+	c.cache[key] = cachedCompletion{values: values, expires: now.Add(completionCacheTTL)}
+	c.mu.Unlock()
+
+	return values, nil
+}
+
+// argumentsKey encodes arguments in a stable order
+func argumentsKey(arguments map[string]string) string {
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(arguments[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// filterCompletions keeps the candidates containing value, ignoring case.
+// Candidates starting with value come first.
+func filterCompletions(candidates []string, value string) Completion {
+	value = strings.ToLower(value)
+
+	var prefixed, contained []string
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		lower := strings.ToLower(candidate)
+		switch {
+		case strings.HasPrefix(lower, value):
+			prefixed = append(prefixed, candidate)
+		case strings.Contains(lower, value):
+			contained = append(contained, candidate)
+		}
+	}
+
+	values := append(prefixed, contained...)
+	completion := Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+	return completion
+}
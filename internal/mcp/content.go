@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Content block types
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeAudio        = "audio"
+	ContentTypeResource     = "resource"
+	ContentTypeResourceLink = "resource_link"
+)
+
+// TextContent returns a text content block
+func TextContent(text string) Content {
+	return Content{Type: ContentTypeText, Text: text}
+}
+
+// ImageContent returns an image content block, e.g. a rendered PNG
+func ImageContent(data []byte, mimeType string) Content {
+	return Content{
+		Type:     ContentTypeImage,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// AudioContent returns an audio content block
+func AudioContent(data []byte, mimeType string) Content {
+	return Content{
+		Type:     ContentTypeAudio,
+		Data:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// EmbeddedResource returns a content block carrying the resource contents
+// inline, e.g. a container log attached as text/plain
+func EmbeddedResource(resource ResourceContents) Content {
+	return Content{Type: ContentTypeResource, Resource: &resource}
+}
+
+// ResourceLink returns a content block pointing at a resource the client
+// can fetch or open itself
+func ResourceLink(resource Resource) Content {
+	return Content{
+		Type:        ContentTypeResourceLink,
+		URI:         resource.URI,
+		Name:        resource.Name,
+		Description: resource.Description,
+		MimeType:    resource.MimeType,
+	}
+}
+
+// MarshalJSON always emits the text field of text blocks, even when empty
+func (c Content) MarshalJSON() ([]byte, error) {
+	type content Content
+	if c.Type == ContentTypeText {
+		return json.Marshal(struct {
+			content
+			Text string `json:"text"`
+		}{content(c), c.Text})
+	}
+	return json.Marshal(content(c))
+}
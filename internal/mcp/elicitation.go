@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrElicitationUnsupported is returned by Elicit when the client did
+	// not declare the elicitation capability
+	ErrElicitationUnsupported = errors.New("client does not support elicitation")
+
+	// ErrDeclined is returned when the user declines or cancels an
+	// elicitation that a tool cannot go on without
+	ErrDeclined = errors.New("declined by the user")
+)
+
+// confirmField is the checkbox of a confirmation form
+const confirmField = "confirm"
+
+// CanElicit reports whether the client of the request being handled in ctx
+// can be asked for input with Elicit: it supports elicitation and is
+// reading a stream the request can be sent on
+func CanElicit(ctx context.Context) bool {
+	scope := scopeFrom(ctx)
+	if scope == nil {
+		return false
+	}
+	return scope.sess.ClientCapabilities().Elicitation != nil &&
+		scope.sess.ProtocolVersion() >= versionElicitation &&
+		scope.sess.canRequest(ctx)
+}
+
+// Elicit asks the user, through the client of the request being handled in
+// ctx, for the values described by schema. The handler waits until the
+// user answers; the result says whether they accepted, declined or
+// cancelled.
+func Elicit(ctx context.Context, message string, schema InputSchema) (*ElicitResult, error) {
+	if !CanElicit(ctx) {
+		return nil, ErrElicitationUnsupported
+	}
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+
+	var result ElicitResult
+	err := scopeFrom(ctx).server.Request(ctx, "elicitation/create", ElicitRequest{
+		Message:         message,
+		RequestedSchema: schema,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	switch result.Action {
+	case ElicitAccept:
+		// Check the answer like tool arguments, so that handlers can
+		// rely on the schema
+		content := schema.coerce(result.Content)
+		if err := schema.Validate(content); err != nil {
+			return nil, fmt.Errorf("invalid answer: %w", err)
+		}
+		result.Content = content
+	case ElicitDecline, ElicitCancel:
+		result.Content = nil
+	default:
+		return nil, fmt.Errorf("invalid elicitation action %q", result.Action)
+	}
+	return &result, nil
+}
+
+// Confirm asks the user to confirm an action, reporting whether they did.
+// The user confirms by ticking a checkbox and accepting the form.
+func Confirm(ctx context.Context, message string) (bool, error) {
+	result, err := Elicit(ctx, message, InputSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			confirmField: {
+				Type:        "boolean",
+				Title:       "Confirm",
+				Description: "Tick to go ahead",
+				Default:     false,
+			},
+		},
+		Required: []string{confirmField},
+	})
+	if err != nil {
+		return false, err
+	}
+	confirmed, _ := result.Content[confirmField].(bool)
+	return result.Action == ElicitAccept && confirmed, nil
+}
+
+// SetConfirmDestructive controls whether destructive tool calls are first
+// confirmed by the user, on clients that support elicitation. Clients
+// without it are not asked. Confirmation is on by default.
+func (s *Server) SetConfirmDestructive(confirm bool) {
+	s.confirmDestructive = confirm
+}
+
+// confirmToolCall asks the user to allow a destructive tool call
+func confirmToolCall(ctx context.Context, tool *Tool, arguments map[string]interface{}) (bool, error) {
+	title := tool.Name
+	if tool.Annotations != nil && tool.Annotations.Title != "" {
+		title = tool.Annotations.Title
+	}
+	args, _ := json.Marshal(arguments)
+	return Confirm(ctx, fmt.Sprintf("%s (%s) may stop, delete or overwrite data. Run it with %s?", title, tool.Name, args))
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"time"
+)
+
+// listChangedDelay coalesces the notifications of changes made together,
+// such as registering all tools of a backend, into one per list
+const listChangedDelay = 100 * time.Millisecond
+
+// beginSession starts tracking a connected session so it can be told when
+// the tool or resource lists change. Sessions whose transport cannot carry
+// notifications after a response are not tracked.
+func (s *Server) beginSession(sess *Session) {
+	if _, ok := sess.transport.(persistentTransport); !ok {
+		return
+	}
+
+	s.sessMu.Lock()
+	defer s.sessMu.Unlock()
+	s.sessions[sess] = struct{}{}
+}
+
+// endSession forgets a session that has ended, fails its requests to the
+// client and drops its subscriptions
+func (s *Server) endSession(sess *Session) {
+	sess.close()
+
+	s.sessMu.Lock()
+	delete(s.sessions, sess)
+	s.sessMu.Unlock()
+
+	s.dropSubscriptions(sess)
+}
+
+// notifyListChanged sends a list_changed notification, such as
+// notifications/tools/list_changed, to every connected session that has
+// finished initializing, once the current burst of changes is over
+func (s *Server) notifyListChanged(method string) {
+	s.sessMu.Lock()
+	defer s.sessMu.Unlock()
+
+	if len(s.sessions) == 0 || s.pendingChanges[method] {
+		return
+	}
+	s.pendingChanges[method] = true
+
+	time.AfterFunc(listChangedDelay, func() {
+		s.sessMu.Lock()
+		delete(s.pendingChanges, method)
+		sessions := make([]*Session, 0, len(s.sessions))
+		for sess := range s.sessions {
+			if sess.isReady() {
+				sessions = append(sessions, sess)
+			}
+		}
+		s.sessMu.Unlock()
+
+		notification := newNotification(method, nil)
+		for _, sess := range sessions {
+			if err := sess.transport.Send(context.Background(), notification); err != nil {
+				s.logger.Warn("Failed to send notification", "method", method, "error", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultClientRequestTimeout bounds how long the server waits for the
+// client to answer a request such as sampling/createMessage
+const defaultClientRequestTimeout = 2 * time.Minute
+
+var (
+	// ErrSessionClosed is returned for requests to a client that has
+	// disconnected
+	ErrSessionClosed = errors.New("session closed")
+
+	// ErrNoSession is returned when a server-to-client request is made
+	// outside the handling of a client request
+	ErrNoSession = errors.New("no client session in context")
+)
+
+// Error implements error, so that a client's error response can be
+// returned from Server.Request
+func (e *JSONRPCError) Error() string {
+	if e.Data != nil {
+		return fmt.Sprintf("%s (%d): %v", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// requestScope is the server and session handling a client request
+type requestScope struct {
+	server *Server
+	sess   *Session
+}
+
+type scopeKey struct{}
+
+// withScope records in ctx the session a request arrived on, so that tool
+// handlers can send requests back to its client
+func withScope(ctx context.Context, s *Server, sess *Session) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &requestScope{server: s, sess: sess})
+}
+
+func scopeFrom(ctx context.Context) *requestScope {
+	scope, _ := ctx.Value(scopeKey{}).(*requestScope)
+	return scope
+}
+
+// ClientSession returns the session of the client request being handled
+// in ctx, or nil outside of request handling
+func ClientSession(ctx context.Context) *Session {
+	if scope := scopeFrom(ctx); scope != nil {
+		return scope.sess
+	}
+	return nil
+}
+
+// SetClientRequestTimeout sets how long the server waits for a client to
+// answer a server-initiated request. Values below or equal to 0 restore
+// the default.
+func (s *Server) SetClientRequestTimeout(d time.Duration) {
+	if d <= 0 {
+		d = defaultClientRequestTimeout
+	}
+	s.clientRequestTimeout = d
+}
+
+// Request sends a request to the client of the request being handled in
+// ctx and decodes the client's result into result. It fails when the
+// client answers with an error (a *JSONRPCError), does not answer within
+// the client request timeout, or ctx is done first; the client is then
+// told that the request was cancelled.
+func (s *Server) Request(ctx context.Context, method string, params, result interface{}) error {
+	scope := scopeFrom(ctx)
+	if scope == nil {
+		return ErrNoSession
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.clientRequestTimeout)
+	defer cancel()
+
+	err := scope.sess.request(ctx, method, params, result)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: client did not answer within %s", method, s.clientRequestTimeout)
+	}
+	return err
+}
+
+// request sends a request to the client and waits for its response
+func (sess *Session) request(ctx context.Context, method string, params, result interface{}) error {
+	if !sess.canRequest(ctx) {
+		return fmt.Errorf("%s: %w", method, ErrNoBackChannel)
+	}
+
+	sess.mu.Lock()
+	if sess.closed {
+		sess.mu.Unlock()
+		return ErrSessionClosed
+	}
+	sess.lastOutboundID++
+	id := sess.lastOutboundID
+	key := requestKey(id)
+	reply := make(chan *JSONRPCResponse, 1)
+	sess.outbound[key] = reply
+	sess.mu.Unlock()
+
+	forget := func() {
+		sess.mu.Lock()
+		delete(sess.outbound, key)
+		sess.mu.Unlock()
+	}
+
+	req := &JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}
+	if err := sess.transport.Send(ctx, req); err != nil {
+		forget()
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	select {
+	case resp := <-reply:
+		if resp == nil {
+			return ErrSessionClosed
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		raw, _ := resp.Result.(json.RawMessage)
+		if err := json.Unmarshal(raw, result); err != nil {
+			return fmt.Errorf("%s: invalid result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		forget()
+		// The client may still be working on it, e.g. waiting for the user
+		sess.transport.Send(context.Background(), newNotification("notifications/cancelled", CancelledNotification{
+			RequestID: id,
+			Reason:    ctx.Err().Error(),
+		}))
+		return ctx.Err()
+	}
+}
+
+// deliverResponse passes a client's response to the request waiting for
+// it, reporting whether one was
+func (sess *Session) deliverResponse(msg *JSONRPCRequest) bool {
+	key := requestKey(msg.ID)
+
+	sess.mu.Lock()
+	reply, ok := sess.outbound[key]
+	delete(sess.outbound, key)
+	sess.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	reply <- &JSONRPCResponse{JSONRPC: "2.0", ID: msg.ID, Result: msg.Result, Error: msg.Error}
+	return true
+}
+
+// close fails the requests still waiting for the client, which has gone
+func (sess *Session) close() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.closed = true
+	for key, reply := range sess.outbound {
+		close(reply)
+		delete(sess.outbound, key)
+	}
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// defaultPageSize is how many items a list method returns per page
+const defaultPageSize = 50
+
+// ErrInvalidCursor is returned for a cursor the server did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Lists whose items are ordered for pagination
+const (
+	listTools     = "tools"
+	listResources = "resources"
+	listTemplates = "templates"
+	listPrompts   = "prompts"
+)
+
+// listKey identifies an item of a list: a tool or prompt name, or a
+// resource or resource template URI
+type listKey struct {
+	list string
+	key  string
+}
+
+// pageCursor is the decoded form of an opaque list cursor. Every item is
+// numbered when registered, and registering appends to its list, so each
+// list is in numbering order. A page resumes after the last number already
+// returned, which stays correct when items are registered or removed
+// between pages. The cursor names its list and the server's epoch, so
+// that it is accepted by neither another list method nor a restarted
+// server.
+type pageCursor struct {
+	Epoch string `json:"e"`
+	List  string `json:"l"`
+	Seq   uint64 `json:"s"`
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor decodes a cursor of list. Cursors this server did not issue
+// for list, such as those of another list, of an earlier process or with
+// a number not yet given out, are invalid. The caller must hold mu.
+func (s *Server) decodeCursor(cursor, list string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Epoch != s.cursorEpoch || c.List != list ||
+		c.Seq == 0 || c.Seq > s.itemSeq {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// numberItem gives a newly registered item the next number. The caller
+// must hold mu.
+func (s *Server) numberItem(list, key string) {
+	s.itemSeq++
+	s.itemNumbers[listKey{list, key}] = s.itemSeq
+}
+
+// forgetItem drops the number of a removed item. The caller must hold mu.
+func (s *Server) forgetItem(list, key string) {
+	delete(s.itemNumbers, listKey{list, key})
+}
+
+// paginate returns the page of items that follows cursor, and the cursor
+// of the page after it, empty on the last page. key identifies an item in
+// list. The caller must hold mu.
+func paginate[T any](s *Server, items []T, cursor, list string, key func(T) string) ([]T, string, error) {
+	seq := func(i int) uint64 { return s.itemNumbers[listKey{list, key(items[i])}] }
+
+	start := 0
+	if cursor != "" {
+		c, err := s.decodeCursor(cursor, list)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(items), func(i int) bool { return seq(i) > c.Seq })
+	}
+
+	end := start + s.pageSize
+	if end >= len(items) {
+		return items[start:], "", nil
+	}
+	next := pageCursor{Epoch: s.cursorEpoch, List: list, Seq: seq(end - 1)}
+	return items[start:end], next.encode(), nil
+}
+
+// SetPageSize sets how many items tools/list, resources/list,
+// resources/templates/list and prompts/list return per page. Values below
+// 1 restore the default.
+func (s *Server) SetPageSize(n int) {
+	if n < 1 {
+		n = defaultPageSize
+	}
+	s.pageSize = n
+}
+
+// ListTools returns the page of tools that follows cursor, and the cursor
+// of the next page, empty on the last page. An empty cursor starts at the
+// first tool. Only tools the caller in ctx may use are listed.
+func (s *Server) ListTools(ctx context.Context, cursor string) ([]Tool, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tools := visible(ctx, s.tools, allowTool)
+	return paginate(s, tools, cursor, listTools, func(t Tool) string { return t.Name })
+}
+
+// listCursor decodes the cursor of a list request
+func listCursor(req *JSONRPCRequest) (string, *JSONRPCResponse) {
+	var params PaginatedRequest
+	if err := decodeParams(req, &params); err != nil {
+		return "", errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+	return params.Cursor, nil
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// listPage requests a page of a list method and returns the names or URIs
+// on it and the next cursor, or the error code
+func listPage(t *testing.T, s *Server, sess *Session, method, cursor string) ([]string, string, int) {
+	t.Helper()
+	params, _ := json.Marshal(map[string]string{"cursor": cursor})
+	req := &JSONRPCRequest{JSONRPC: "2.0", ID: float64(1), Method: method, Params: json.RawMessage(params)}
+	resp := s.Dispatch(context.Background(), sess, req)
+	if resp.Error != nil {
+		return nil, "", resp.Error.Code
+	}
+
+	data, err := json.Marshal(resp.Result)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	var result struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+		Resources []struct {
+			URI string `json:"uri"`
+		} `json:"resources"`
+		ResourceTemplates []struct {
+			URITemplate string `json:"uriTemplate"`
+		} `json:"resourceTemplates"`
+		Prompts []struct {
+			Name string `json:"name"`
+		} `json:"prompts"`
+		NextCursor string `json:"nextCursor"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+
+	var keys []string
+	for _, item := range result.Tools {
+		keys = append(keys, item.Name)
+	}
+	for _, item := range result.Resources {
+		keys = append(keys, item.URI)
+	}
+	for _, item := range result.ResourceTemplates {
+		keys = append(keys, item.URITemplate)
+	}
+	for _, item := range result.Prompts {
+		keys = append(keys, item.Name)
+	}
+	return keys, result.NextCursor, 0
+}
+
+func registerTestTools(s *Server, names ...string) {
+	for _, name := range names {
+		s.RegisterTool(Tool{Name: name, InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnly(name)},
+			func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+				return name, nil
+			})
+	}
+}
+
+func TestPaginationPages(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	s.SetPageSize(2)
+	registerTestTools(s, "t1", "t2", "t3", "t4", "t5")
+	for i := 1; i <= 3; i++ {
+		s.RegisterResource(Resource{URI: fmt.Sprintf("test://r%d", i), Name: fmt.Sprintf("r%d", i)}, nil)
+		s.RegisterPrompt(Prompt{Name: fmt.Sprintf("p%d", i)}, nil)
+		s.RegisterResourceTemplate(ResourceTemplate{URITemplate: fmt.Sprintf("test://t%d/{id}", i), Name: fmt.Sprintf("t%d", i)}, nil)
+	}
+	sess := initializedSession(t, s)
+
+	tests := []struct {
+		method string
+		want   [][]string
+	}{
+		{"tools/list", [][]string{{"t1", "t2"}, {"t3", "t4"}, {"t5"}}},
+		{"resources/list", [][]string{{"test://r1", "test://r2"}, {"test://r3"}}},
+		{"resources/templates/list", [][]string{{"test://t1/{id}", "test://t2/{id}"}, {"test://t3/{id}"}}},
+		{"prompts/list", [][]string{{"p1", "p2"}, {"p3"}}},
+	}
+
+	for _, tt := range tests {
+		var pages [][]string
+		cursor := ""
+		for {
+			keys, next, code := listPage(t, s, sess, tt.method, cursor)
+			if code != 0 {
+				t.Fatalf("%s: error %d", tt.method, code)
+			}
+			pages = append(pages, keys)
+			if next == "" {
+				break
+			}
+			if len(pages) > len(tt.want) {
+				t.Fatalf("%s: more pages than the %d expected", tt.method, len(tt.want))
+			}
+			cursor = next
+		}
+		if !reflect.DeepEqual(pages, tt.want) {
+			t.Errorf("%s: pages %v, want %v", tt.method, pages, tt.want)
+		}
+	}
+}
+
+func TestPaginationCursorStability(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	s.SetPageSize(2)
+	registerTestTools(s, "t1", "t2", "t3", "t4", "t5")
+	sess := initializedSession(t, s)
+
+	keys, cursor, _ := listPage(t, s, sess, "tools/list", "")
+	if !reflect.DeepEqual(keys, []string{"t1", "t2"}) {
+		t.Fatalf("first page %v", keys)
+	}
+
+	// The last tool returned and the next one go away, a tool is added and
+	// t1 is registered again, which moves it to the end
+	s.UnregisterTool("t2")
+	s.UnregisterTool("t3")
+	registerTestTools(s, "t6", "t1")
+
+	keys, cursor, _ = listPage(t, s, sess, "tools/list", cursor)
+	if !reflect.DeepEqual(keys, []string{"t4", "t5"}) {
+		t.Fatalf("second page %v, want [t4 t5]", keys)
+	}
+	keys, cursor, _ = listPage(t, s, sess, "tools/list", cursor)
+	if !reflect.DeepEqual(keys, []string{"t6", "t1"}) || cursor != "" {
+		t.Fatalf("last page %v with cursor %q, want [t6 t1] and no cursor", keys, cursor)
+	}
+}
+
+func TestPaginationResourceCursorStability(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	s.SetPageSize(1)
+	for i := 1; i <= 3; i++ {
+		s.RegisterResource(Resource{URI: fmt.Sprintf("test://r%d", i), Name: fmt.Sprintf("r%d", i)}, nil)
+	}
+	sess := initializedSession(t, s)
+
+	_, cursor, _ := listPage(t, s, sess, "resources/list", "")
+	s.UnregisterResource("test://r1")
+	s.RegisterResource(Resource{URI: "test://r0", Name: "r0"}, nil)
+
+	var rest []string
+	for cursor != "" {
+		var keys []string
+		keys, cursor, _ = listPage(t, s, sess, "resources/list", cursor)
+		rest = append(rest, keys...)
+	}
+	if want := []string{"test://r2", "test://r3", "test://r0"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("after the first page: %v, want %v", rest, want)
+	}
+}
+
+func TestPaginationInvalidCursor(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	registerTestTools(s, "t1")
+	sess := initializedSession(t, s)
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	cursors := map[string]string{
+		"not base64":  "!!!",
+		"not JSON":    encode("seq 1"),
+		"no position": encode(`{}`),
+		"position 0":  encode(`{"s":0}`),
+		"wrong type":  encode(`{"s":"1"}`),
+		"padded":      base64.URLEncoding.EncodeToString([]byte(`{"s":1}`)),
+	}
+
+	for _, method := range []string{"tools/list", "resources/list", "resources/templates/list", "prompts/list"} {
+		for name, cursor := range cursors {
+			if _, _, code := listPage(t, s, sess, method, cursor); code != InvalidParams {
+				t.Errorf("%s with cursor %s: code %d, want %d", method, name, code, InvalidParams)
+			}
+		}
+
+		req := &JSONRPCRequest{JSONRPC: "2.0", ID: float64(1), Method: method, Params: json.RawMessage(`{"cursor":1}`)}
+		if resp := s.Dispatch(context.Background(), sess, req); resp.Error == nil || resp.Error.Code != InvalidParams {
+			t.Errorf("%s with a numeric cursor: %+v, want code %d", method, resp.Error, InvalidParams)
+		}
+	}
+}
+
+func TestPaginationStaleCursor(t *testing.T) {
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	s.SetPageSize(1)
+	registerTestTools(s, "t1", "t2")
+	s.RegisterPrompt(Prompt{Name: "p1"}, nil)
+	s.RegisterPrompt(Prompt{Name: "p2"}, nil)
+	sess := initializedSession(t, s)
+
+	_, cursor, _ := listPage(t, s, sess, "tools/list", "")
+	if keys, _, code := listPage(t, s, sess, "tools/list", cursor); code != 0 || !reflect.DeepEqual(keys, []string{"t2"}) {
+		t.Fatalf("second page %v (code %d), want [t2]", keys, code)
+	}
+
+	// A restarted server numbers the same tools alike
+	restarted := NewServer("test", "1.0.0", LatestProtocolVersion)
+	restarted.SetPageSize(1)
+	registerTestTools(restarted, "t1", "t2")
+
+	tests := []struct {
+		name   string
+		s      *Server
+		method string
+		cursor string
+	}{
+		{"other list", s, "prompts/list", cursor},
+		{"restarted server", restarted, "tools/list", cursor},
+		{"number not issued", s, "tools/list", pageCursor{Epoch: s.cursorEpoch, List: listTools, Seq: 100}.encode()},
+	}
+
+	for _, tt := range tests {
+		if _, _, code := listPage(t, tt.s, initializedSession(t, tt.s), tt.method, tt.cursor); code != InvalidParams {
+			t.Errorf("%s: code %d, want %d", tt.name, code, InvalidParams)
+		}
+	}
+}
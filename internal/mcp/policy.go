@@ -0,0 +1,62 @@
+package mcp
+
+import "context"
+
+// ToolAttributesFunc describes what a tool call acts on, for tool policies
+// to match, e.g. the labels of the container a tool would restart
+type ToolAttributesFunc func(ctx context.Context, arguments map[string]interface{}) (map[string]string, error)
+
+// ToolPolicy decides whether tool calls may run
+type ToolPolicy interface {
+	// CheckToolCall returns an error saying why call may not run, or nil
+	// to let it run
+	CheckToolCall(ctx context.Context, call ToolCall) error
+}
+
+// ToolCall is a tool call about to run, with its arguments already
+// validated
+type ToolCall struct {
+	Tool      Tool
+	Arguments map[string]interface{}
+
+	attributes ToolAttributesFunc
+}
+
+// Attributes returns the attributes registered for the call's tool, which
+// may look up what the call acts on. Tools without attributes have none.
+func (c ToolCall) Attributes(ctx context.Context) (map[string]string, error) {
+	if c.attributes == nil {
+		return nil, nil
+	}
+	return c.attributes(ctx, c.Arguments)
+}
+
+// SetToolPolicy sets the policy every tool call is checked against on all
+// transports. Denied calls return an error result. A nil policy lets all
+// calls run.
+func (s *Server) SetToolPolicy(p ToolPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolPolicy = p
+}
+
+// RegisterToolAttributes registers the attributes of calls to tool. They
+// are removed along with the tool.
+func (s *Server) RegisterToolAttributes(tool string, attributes ToolAttributesFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolAttributes[tool] = attributes
+}
+
+// RegisterTypedToolAttributes registers the attributes of calls to tool,
+// computed from the call's arguments decoded into T like those of a typed
+// tool
+func RegisterTypedToolAttributes[T any](s *Server, tool string, attributes func(ctx context.Context, args T) (map[string]string, error)) {
+	s.RegisterToolAttributes(tool, func(ctx context.Context, arguments map[string]interface{}) (map[string]string, error) {
+		var args T
+		if err := decodeArguments(arguments, &args); err != nil {
+			return nil, err
+		}
+		return attributes(ctx, args)
+	})
+}
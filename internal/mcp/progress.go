@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressReporter emits notifications/progress for the request being
+// handled. Tool handlers obtain one with Progress; a nil reporter (the
+// client did not send a progress token) silently discards reports.
+type ProgressReporter struct {
+	ctx   context.Context
+	sess  *Session
+	token interface{}
+
+	mu   sync.Mutex
+	last float64
+}
+
+type progressKey struct{}
+
+// withProgress attaches a progress reporter for token to ctx
+func withProgress(ctx context.Context, sess *Session, token interface{}) context.Context {
+	reporter := &ProgressReporter{sess: sess, token: token, last: -1}
+	ctx = context.WithValue(ctx, progressKey{}, reporter)
+	reporter.ctx = ctx
+	return ctx
+}
+
+// Progress returns the progress reporter for the request in ctx. It returns
+// nil when the client did not ask for progress, which is safe to use.
+func Progress(ctx context.Context) *ProgressReporter {
+	reporter, _ := ctx.Value(progressKey{}).(*ProgressReporter)
+	return reporter
+}
+
+// Report sends a progress update. total may be 0 when unknown. Progress must
+// increase with every call; updates that do not are dropped.
+func (p *ProgressReporter) Report(progress, total float64, message string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if progress <= p.last {
+		p.mu.Unlock()
+		return
+	}
+	p.last = progress
+	p.mu.Unlock()
+
+	// A finished or cancelled request must not produce further notifications
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	notification := newNotification("notifications/progress", ProgressNotification{
+		ProgressToken: p.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+	// Progress is best effort; transports without a back channel drop it
+	p.sess.transport.Send(p.ctx, notification)
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RateLimitError refuses a request that exceeds a rate limit or
+// concurrency cap. Tool handlers and resource handlers may return it,
+// wrapped or not, to answer with a RateLimited error instead of an error
+// result.
+type RateLimitError struct {
+	// Limit names the limit that was exceeded, e.g. "caller ci-bot" or
+	// "backend prometheus"
+	Limit string
+
+	// RetryAfter is how long the client should wait before retrying
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
+
+// RateLimitData is the data of a RateLimited error
+type RateLimitData struct {
+	Limit string `json:"limit"`
+
+	// RetryAfter is in whole seconds, rounded up, like the HTTP
+	// Retry-After header
+	RetryAfter int `json:"retryAfter"`
+}
+
+// ToolCallLimiter limits how often tools may be called
+type ToolCallLimiter interface {
+	// LimitToolCall returns a *RateLimitError when call may not run now
+	LimitToolCall(ctx context.Context, call ToolCall) error
+}
+
+// SetToolCallLimiter sets the limiter every tool call is checked against
+// on all transports, before its arguments are validated. A nil limiter
+// lets all calls run.
+func (s *Server) SetToolCallLimiter(l ToolCallLimiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolCallLimiter = l
+}
+
+// rateLimitResponse answers a request refused by a rate limit, if err is
+// one
+func rateLimitResponse(id interface{}, err error) (*JSONRPCResponse, bool) {
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) {
+		return nil, false
+	}
+	return errorResponse(id, RateLimited, "Rate limit exceeded", RateLimitData{
+		Limit:      limitErr.Limit,
+		RetryAfter: int(math.Ceil(limitErr.RetryAfter.Seconds())),
+	}), true
+}
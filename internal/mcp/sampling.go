@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrSamplingUnsupported is returned by CreateMessage when the client did
+// not declare the sampling capability
+var ErrSamplingUnsupported = errors.New("client does not support sampling")
+
+// CreateMessage asks the client of the request being handled in ctx to
+// sample its LLM with sampling/createMessage, e.g. to summarize output too
+// large to return as is. The client may show the request to the user,
+// change it or refuse it. Tool handlers should fall back to their plain
+// output when it fails.
+func CreateMessage(ctx context.Context, req CreateMessageRequest) (*CreateMessageResult, error) {
+	scope := scopeFrom(ctx)
+	if scope == nil {
+		return nil, ErrNoSession
+	}
+	if scope.sess.ClientCapabilities().Sampling == nil {
+		return nil, ErrSamplingUnsupported
+	}
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("sampling request has no messages")
+	}
+	if req.MaxTokens <= 0 {
+		return nil, fmt.Errorf("sampling request needs a positive maxTokens")
+	}
+
+	var result CreateMessageResult
+	if err := scope.server.Request(ctx, "sampling/createMessage", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Text returns the text of a sampling result, or "" if the client
+// answered with other content
+func (r *CreateMessageResult) Text() string {
+	if r == nil || r.Content.Type != "text" {
+		return ""
+	}
+	return r.Content.Text
+}
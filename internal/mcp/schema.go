@@ -0,0 +1,353 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Float returns a pointer to v, for Property.Minimum and Property.Maximum
+func Float(v float64) *float64 {
+	return &v
+}
+
+// ValidationError reports where tool arguments violate the input schema
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate checks tool arguments against the schema. Only the keywords
+// supported by Property are enforced; unknown formats are ignored, as JSON
+// Schema treats format as an annotation.
+func (s InputSchema) Validate(arguments map[string]interface{}) error {
+	root := Property{
+		Type:       s.Type,
+		Properties: s.Properties,
+		Required:   s.Required,
+	}
+
+	var value interface{} = arguments
+	if arguments == nil {
+		value = map[string]interface{}{}
+	}
+	return validateValue("arguments", root, value)
+}
+
+// coerce converts loosely typed arguments to the types the schema declares
+// and fills in defaults for omitted properties, so every tool accepts the
+// same inputs: numeric strings for numbers, "true" and "false" for booleans,
+// numbers for strings and a single value for an array. Values that cannot be
+// converted are left as they are for Validate to reject.
+func (s InputSchema) coerce(arguments map[string]interface{}) map[string]interface{} {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	return coerceObject(s.Properties, arguments)
+}
+
+func coerceObject(properties map[string]Property, object map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(object))
+	for name, value := range object {
+		out[name] = value
+	}
+
+	for name, prop := range properties {
+		if value, ok := out[name]; ok {
+			out[name] = coerceValue(prop, value)
+		} else if prop.Default != nil {
+			out[name] = normalizeDefault(prop.Default)
+		}
+	}
+	return out
+}
+
+func coerceValue(prop Property, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch prop.Type {
+	case "number", "integer":
+		if str, ok := value.(string); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(str), 64); err == nil {
+				return f
+			}
+		}
+
+	case "boolean":
+		switch value {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+
+	case "string":
+		if f, ok := value.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		if prop.Items == nil {
+			return items
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = coerceValue(*prop.Items, item)
+		}
+		return out
+
+	case "object":
+		if object, ok := value.(map[string]interface{}); ok {
+			return coerceObject(prop.Properties, object)
+		}
+	}
+
+	return value
+}
+
+// normalizeDefault converts a schema default to its decoded JSON form, so
+// handlers see float64 rather than int for numeric defaults
+func normalizeDefault(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+func validateValue(path string, prop Property, value interface{}) error {
+	if len(prop.OneOf) > 0 {
+		if err := validateOneOf(path, prop.OneOf, value); err != nil {
+			return err
+		}
+	}
+
+	if prop.Type != "" && !matchesType(prop.Type, value) {
+		return &ValidationError{path, fmt.Sprintf("expected %s, got %s", prop.Type, jsonType(value))}
+	}
+
+	if len(prop.Enum) > 0 {
+		str, ok := value.(string)
+		if !ok || !contains(prop.Enum, str) {
+			return &ValidationError{path, fmt.Sprintf("must be one of %s", strings.Join(prop.Enum, ", "))}
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if prop.Minimum != nil && v < *prop.Minimum {
+			return &ValidationError{path, fmt.Sprintf("must be >= %v", *prop.Minimum)}
+		}
+		if prop.Maximum != nil && v > *prop.Maximum {
+			return &ValidationError{path, fmt.Sprintf("must be <= %v", *prop.Maximum)}
+		}
+
+	case string:
+		if prop.Pattern != "" {
+			re, err := compilePattern(prop.Pattern)
+			if err != nil {
+				return &ValidationError{path, fmt.Sprintf("invalid schema pattern %q: %v", prop.Pattern, err)}
+			}
+			if !re.MatchString(v) {
+				return &ValidationError{path, fmt.Sprintf("must match pattern %s", prop.Pattern)}
+			}
+		}
+		if prop.Format != "" {
+			if err := checkFormat(prop.Format, v); err != nil {
+				return &ValidationError{path, err.Error()}
+			}
+		}
+
+	case []interface{}:
+		if prop.Items != nil {
+			for i, item := range v {
+				if err := validateValue(fmt.Sprintf("%s[%d]", path, i), *prop.Items, item); err != nil {
+					return err
+				}
+			}
+		}
+
+	case map[string]interface{}:
+		for _, name := range prop.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{path + "." + name, "is required"}
+			}
+		}
+
+		// Walk properties in a stable order so errors are deterministic
+		names := make([]string, 0, len(prop.Properties))
+		for name := range prop.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			field, ok := v[name]
+			if !ok {
+				continue
+			}
+			if err := validateValue(path+"."+name, prop.Properties[name], field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOneOf requires exactly one alternative to accept the value
+func validateOneOf(path string, alternatives []Property, value interface{}) error {
+	matched := 0
+	var firstErr error
+	for _, alt := range alternatives {
+		if err := validateValue(path, alt, value); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		matched++
+	}
+
+	switch {
+	case matched == 1:
+		return nil
+	case matched == 0 && len(alternatives) == 1:
+		return firstErr
+	case matched == 0:
+		return &ValidationError{path, fmt.Sprintf("does not match any allowed schema (%s)", describeAlternatives(alternatives))}
+	default:
+		return &ValidationError{path, "matches more than one allowed schema"}
+	}
+}
+
+func describeAlternatives(alternatives []Property) string {
+	parts := make([]string, 0, len(alternatives))
+	for _, alt := range alternatives {
+		desc := alt.Type
+		if desc == "" {
+			desc = "any"
+		}
+		if alt.Type == "array" && alt.Items != nil && alt.Items.Type != "" {
+			desc += " of " + alt.Items.Type
+		}
+		parts = append(parts, desc)
+	}
+	return strings.Join(parts, " or ")
+}
+
+// matchesType checks a decoded JSON value against a JSON Schema type
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+// jsonType names the JSON type of a decoded value for error messages
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func checkFormat(format, value string) error {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	case "email":
+		_, err = mail.ParseAddress(value)
+	case "uri":
+		var u *url.URL
+		if u, err = url.Parse(value); err == nil && u.Scheme == "" {
+			err = fmt.Errorf("missing scheme")
+		}
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("must be a valid %s", format)
+	}
+	return nil
+}
+
+var patternCache sync.Map // pattern string -> *regexp.Regexp
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testSchema = InputSchema{
+	Type: "object",
+	Properties: map[string]Property{
+		"name":     {Type: "string", Pattern: "^[a-z][a-z0-9-]*$"},
+		"count":    {Type: "integer", Minimum: Float(1), Maximum: Float(10), Default: 5},
+		"ratio":    {Type: "number"},
+		"enabled":  {Type: "boolean"},
+		"level":    {Type: "string", Enum: []string{"info", "warn", "error"}},
+		"since":    {Type: "string", Format: "date-time"},
+		"owner":    {Type: "string", Format: "email"},
+		"link":     {Type: "string", Format: "uri"},
+		"custom":   {Type: "string", Format: "hostname"},
+		"tags":     {Type: "array", Items: &Property{Type: "string"}},
+		"ids":      {Type: "array", Items: &Property{Type: "integer"}},
+		"selector": {OneOf: []Property{{Type: "string"}, {Type: "array", Items: &Property{Type: "string"}}}},
+		"target": {
+			Type:     "object",
+			Required: []string{"id"},
+			Properties: map[string]Property{
+				"id":   {Type: "integer", Minimum: Float(1)},
+				"kind": {Type: "string", Default: "container"},
+			},
+		},
+	},
+	Required: []string{"name"},
+}
+
+func TestInputSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		wantPath  string // empty when the arguments are valid
+	}{
+		{"minimal", map[string]interface{}{"name": "web"}, ""},
+		{"all valid", map[string]interface{}{
+			"name":     "web-1",
+			"count":    float64(10),
+			"ratio":    0.5,
+			"enabled":  true,
+			"level":    "warn",
+			"since":    "2026-10-17T09:00:00Z",
+			"owner":    "ops@example.com",
+			"link":     "https://example.com/runbook",
+			"custom":   "anything goes",
+			"tags":     []interface{}{"a", "b"},
+			"ids":      []interface{}{float64(1), float64(2)},
+			"selector": []interface{}{"a"},
+			"target":   map[string]interface{}{"id": float64(3)},
+		}, ""},
+		{"nil arguments", nil, "arguments.name"},
+		{"missing required", map[string]interface{}{"count": float64(1)}, "arguments.name"},
+		{"wrong type", map[string]interface{}{"name": float64(1)}, "arguments.name"},
+		{"pattern", map[string]interface{}{"name": "Web"}, "arguments.name"},
+		{"not an integer", map[string]interface{}{"name": "web", "count": 1.5}, "arguments.count"},
+		{"below minimum", map[string]interface{}{"name": "web", "count": float64(0)}, "arguments.count"},
+		{"above maximum", map[string]interface{}{"name": "web", "count": float64(11)}, "arguments.count"},
+		{"string for boolean", map[string]interface{}{"name": "web", "enabled": "true"}, "arguments.enabled"},
+		{"enum", map[string]interface{}{"name": "web", "level": "debug"}, "arguments.level"},
+		{"date-time", map[string]interface{}{"name": "web", "since": "yesterday"}, "arguments.since"},
+		{"email", map[string]interface{}{"name": "web", "owner": "ops"}, "arguments.owner"},
+		{"uri without scheme", map[string]interface{}{"name": "web", "link": "example.com"}, "arguments.link"},
+		{"array item", map[string]interface{}{"name": "web", "ids": []interface{}{float64(1), "2"}}, "arguments.ids[1]"},
+		{"one of neither", map[string]interface{}{"name": "web", "selector": float64(1)}, "arguments.selector"},
+		{"nested required", map[string]interface{}{"name": "web", "target": map[string]interface{}{}}, "arguments.target.id"},
+		{"nested property", map[string]interface{}{"name": "web", "target": map[string]interface{}{"id": float64(0)}}, "arguments.target.id"},
+		{"unknown properties are allowed", map[string]interface{}{"name": "web", "extra": true}, ""},
+	}
+
+	for _, tt := range tests {
+		err := testSchema.Validate(tt.arguments)
+		if tt.wantPath == "" {
+			if err != nil {
+				t.Errorf("%s: Validate = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: Validate = %v, want a ValidationError at %s", tt.name, err, tt.wantPath)
+			continue
+		}
+		if verr.Path != tt.wantPath {
+			t.Errorf("%s: error at %s (%v), want %s", tt.name, verr.Path, verr, tt.wantPath)
+		}
+	}
+}
+
+func TestOneOfMatchingBoth(t *testing.T) {
+	prop := Property{OneOf: []Property{{Type: "number"}, {Type: "integer"}}}
+	if err := validateValue("value", prop, float64(1)); err == nil {
+		t.Error("value matching both alternatives was accepted")
+	}
+	if err := validateValue("value", prop, 1.5); err != nil {
+		t.Errorf("value matching one alternative: %v", err)
+	}
+}
+
+func TestInputSchemaCoerce(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]interface{}
+		want      map[string]interface{}
+	}{
+		{"defaults", nil, map[string]interface{}{"count": float64(5)}},
+		{
+			"numeric strings",
+			map[string]interface{}{"count": " 3 ", "ratio": "0.25"},
+			map[string]interface{}{"count": float64(3), "ratio": 0.25},
+		},
+		{
+			"boolean strings",
+			map[string]interface{}{"enabled": "false"},
+			map[string]interface{}{"count": float64(5), "enabled": false},
+		},
+		{
+			"number for string",
+			map[string]interface{}{"name": float64(42), "level": 1.5},
+			map[string]interface{}{"count": float64(5), "name": "42", "level": "1.5"},
+		},
+		{
+			"single value for array",
+			map[string]interface{}{"tags": "a", "ids": "7"},
+			map[string]interface{}{"count": float64(5), "tags": []interface{}{"a"}, "ids": []interface{}{float64(7)}},
+		},
+		{
+			"array items",
+			map[string]interface{}{"ids": []interface{}{"1", float64(2)}},
+			map[string]interface{}{"count": float64(5), "ids": []interface{}{float64(1), float64(2)}},
+		},
+		{
+			"nested object",
+			map[string]interface{}{"target": map[string]interface{}{"id": "9"}},
+			map[string]interface{}{"count": float64(5), "target": map[string]interface{}{"id": float64(9), "kind": "container"}},
+		},
+		{
+			"unconvertible values are kept",
+			map[string]interface{}{"count": "many", "enabled": "yes", "ratio": nil},
+			map[string]interface{}{"count": "many", "enabled": "yes", "ratio": nil},
+		},
+		{
+			"unknown properties are kept",
+			map[string]interface{}{"extra": "1"},
+			map[string]interface{}{"count": float64(5), "extra": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := testSchema.coerce(tt.arguments); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: coerce(%v) = %v, want %v", tt.name, tt.arguments, got, tt.want)
+		}
+	}
+}
+
+func TestCoerceDoesNotModifyArguments(t *testing.T) {
+	arguments := map[string]interface{}{"count": "3"}
+	testSchema.coerce(arguments)
+	if arguments["count"] != "3" || len(arguments) != 1 {
+		t.Errorf("coerce modified its arguments: %v", arguments)
+	}
+}
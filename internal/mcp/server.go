@@ -4,82 +4,389 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/axinova-ai/axinova-mcp-server-go/internal/metrics"
 )
 
-// ToolHandler is a function that executes a tool
+// ToolHandler is a function that executes a tool. Long-running handlers
+// should report progress through Progress(ctx).
 type ToolHandler func(ctx context.Context, arguments map[string]interface{}) (interface{}, error)
 
 // ResourceHandler is a function that reads a resource
 type ResourceHandler func(ctx context.Context, uri string) (string, string, error) // content, mimeType, error
 
+// ResourceTemplateHandler reads a resource matched by a resource template.
+// params holds the template variables extracted from uri.
+type ResourceTemplateHandler func(ctx context.Context, uri string, params map[string]string) (string, string, error) // content, mimeType, error
+
+// PromptHandler renders a prompt. Arguments have already been checked
+// against the prompt's declared arguments.
+type PromptHandler func(ctx context.Context, arguments map[string]string) (*GetPromptResult, error)
+
+// ErrResourceNotFound is returned by ReadResource when no resource or
+// resource template matches the URI
+var ErrResourceNotFound = errors.New("resource not found")
+
 // Server implements the MCP protocol server
 type Server struct {
 	serverInfo Implementation
 
-	tools     []Tool
-	toolHandlers map[string]ToolHandler
+	// protocolVersions are the versions initialize can negotiate, newest
+	// first
+	protocolVersions []string
+
+	// mu guards the registries below, which may change while serving.
+	// Writers replace slices rather than modifying them in place, so a
+	// slice read under mu stays valid after it is released.
+	mu sync.RWMutex
+
+	tools          []Tool
+	toolHandlers   map[string]ToolHandler
+	toolAttributes map[string]ToolAttributesFunc
 
-	resources []Resource
+	// toolPolicy, when set, decides which tool calls may run, and
+	// toolCallAuditor records them
+	toolPolicy      ToolPolicy
+	toolCallAuditor ToolCallAuditor
+
+	// toolCallLimiter, when set, limits how often tools may be called
+	toolCallLimiter ToolCallLimiter
+
+	resources        []Resource
 	resourceHandlers map[string]ResourceHandler
 
-	prompts []Prompt
+	// resourceTemplates are tried in registration order when no resource
+	// has the exact URI being read
+	resourceTemplates []resourceTemplate
+
+	// watchers make resources subscribable; subscriptions are keyed by URI
+	watchers      []*resourceWatcher
+	subsMu        sync.Mutex
+	subscriptions map[string]*subscription
+	pollInterval  time.Duration
+
+	// sessions are the connected persistent sessions, which are told when
+	// the tool or resource lists change
+	sessMu         sync.Mutex
+	sessions       map[*Session]struct{}
+	pendingChanges map[string]bool
+
+	prompts        []Prompt
+	promptHandlers map[string]PromptHandler
+
+	// completers supply completion/complete candidates; a key without a
+	// reference applies to any argument of that name
+	completers map[completionKey]*completer
+
+	// maxInFlight bounds concurrently handled stdio requests
+	maxInFlight int
+
+	// clientRequestTimeout bounds the wait for the client to answer a
+	// server-initiated request
+	clientRequestTimeout time.Duration
+
+	// pageSize is how many items a list method returns per page, and
+	// itemNumbers orders the items of each list for paging cursors;
+	// guarded by mu. cursorEpoch marks the cursors of this server, so
+	// that those issued before a restart are rejected.
+	pageSize    int
+	itemSeq     uint64
+	itemNumbers map[listKey]uint64
+	cursorEpoch string
+
+	// allowDestructive permits calls to tools that are not known to be
+	// non-destructive, and confirmDestructive has the user confirm them
+	allowDestructive   bool
+	confirmDestructive bool
 
 	input  io.Reader
 	output io.Writer
-	logger *log.Logger
+	logger *slog.Logger
 }
 
+// defaultMaxInFlight is used when no max-in-flight limit is configured
+const defaultMaxInFlight = 16
+
 // NewServer creates a new MCP server
 func NewServer(name, version, protocolVersion string) *Server {
-	return &Server{
+	s := &Server{
 		serverInfo: Implementation{
 			Name:    name,
 			Version: version,
 		},
-		toolHandlers:     make(map[string]ToolHandler),
-		resourceHandlers: make(map[string]ResourceHandler),
-		input:            os.Stdin,
-		output:           os.Stdout,
-		logger:           log.New(os.Stderr, "[MCP] ", log.LstdFlags),
+		protocolVersions:     protocolVersions(protocolVersion),
+		toolHandlers:         make(map[string]ToolHandler),
+		toolAttributes:       make(map[string]ToolAttributesFunc),
+		resourceHandlers:     make(map[string]ResourceHandler),
+		promptHandlers:       make(map[string]PromptHandler),
+		completers:           make(map[completionKey]*completer),
+		itemNumbers:          make(map[listKey]uint64),
+		subscriptions:        make(map[string]*subscription),
+		sessions:             make(map[*Session]struct{}),
+		pendingChanges:       make(map[string]bool),
+		pollInterval:         defaultPollInterval,
+		maxInFlight:          defaultMaxInFlight,
+		clientRequestTimeout: defaultClientRequestTimeout,
+		confirmDestructive:   true,
+		pageSize:             defaultPageSize,
+		cursorEpoch:          newSessionID()[:8],
+		input:                os.Stdin,
+		output:               os.Stdout,
+	}
+	s.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	if protocolVersion != "" && !s.supportsVersion(protocolVersion) {
+		s.logger.Warn("Unknown protocol version, offering all supported versions",
+			"version", protocolVersion, "supported", SupportedProtocolVersions)
 	}
+	return s
 }
 
-// RegisterTool registers a tool with its handler
+// NewStatelessSession returns a session that is already initialized at the
+// server's newest protocol version, for transports where each request is
+// handled on a session of its own and there is no initialize handshake.
+// Clients may still send initialize to negotiate a version, as on stdio.
+func (s *Server) NewStatelessSession(transport Transport) *Session {
+	sess := NewSession(transport)
+	sess.stateless = true
+	sess.initialize(s.protocolVersions[0], Implementation{}, ClientCapabilities{})
+	return sess
+}
+
+// SetMaxInFlight sets how many stdio requests may be handled concurrently.
+// Values below 1 restore the default.
+func (s *Server) SetMaxInFlight(n int) {
+	if n < 1 {
+		n = defaultMaxInFlight
+	}
+	s.maxInFlight = n
+}
+
+// SetAllowDestructive controls whether destructive tools may be called.
+// When disallowed, which is the default, such calls are refused with an
+// error result; the tools are still listed.
+func (s *Server) SetAllowDestructive(allow bool) {
+	s.allowDestructive = allow
+}
+
+// RegisterTool registers a tool with its handler, replacing any tool of
+// the same name. Connected clients are notified that the tool list changed.
 func (s *Server) RegisterTool(tool Tool, handler ToolHandler) {
-	s.tools = append(s.tools, tool)
+	s.mu.Lock()
+	tools := make([]Tool, 0, len(s.tools)+1)
+	for _, t := range s.tools {
+		if t.Name != tool.Name {
+			tools = append(tools, t)
+		}
+	}
+	s.tools = append(tools, tool)
 	s.toolHandlers[tool.Name] = handler
-	metrics.RecordToolsRegistered(len(s.tools))
+	s.numberItem(listTools, tool.Name)
+	count := len(s.tools)
+	s.mu.Unlock()
+
+	metrics.RecordToolsRegistered(count)
+	s.notifyListChanged("notifications/tools/list_changed")
 }
 
-// RegisterResource registers a resource with its handler
+// RegisterResource registers a resource with its handler, replacing any
+// resource with the same URI. Connected clients are notified that the
+// resource list changed.
 func (s *Server) RegisterResource(resource Resource, handler ResourceHandler) {
-	s.resources = append(s.resources, resource)
+	s.mu.Lock()
+	resources := make([]Resource, 0, len(s.resources)+1)
+	for _, r := range s.resources {
+		if r.URI != resource.URI {
+			resources = append(resources, r)
+		}
+	}
+	s.resources = append(resources, resource)
 	s.resourceHandlers[resource.URI] = handler
-	metrics.RecordResourcesRegistered(len(s.resources))
+	s.numberItem(listResources, resource.URI)
+	count := len(s.resources)
+	s.mu.Unlock()
+
+	metrics.RecordResourcesRegistered(count)
+	s.notifyListChanged("notifications/resources/list_changed")
+}
+
+// RegisterResourceTemplate registers a resource template with the handler
+// for URIs matching it, replacing any template written the same way. It
+// panics if the URI template is invalid.
+func (s *Server) RegisterResourceTemplate(template ResourceTemplate, handler ResourceTemplateHandler) {
+	t := resourceTemplate{
+		ResourceTemplate: template,
+		uriTemplate:      MustParseURITemplate(template.URITemplate),
+		handler:          handler,
+	}
+
+	s.mu.Lock()
+	templates := make([]resourceTemplate, 0, len(s.resourceTemplates)+1)
+	for _, existing := range s.resourceTemplates {
+		if existing.URITemplate != template.URITemplate {
+			templates = append(templates, existing)
+		}
+	}
+	s.resourceTemplates = append(templates, t)
+	s.numberItem(listTemplates, template.URITemplate)
+	s.mu.Unlock()
+
+	s.notifyListChanged("notifications/resources/list_changed")
+}
+
+type resourceTemplate struct {
+	ResourceTemplate
+	uriTemplate *URITemplate
+	handler     ResourceTemplateHandler
+}
+
+// UnregisterTool removes a tool, reporting whether it was registered
+func (s *Server) UnregisterTool(name string) bool {
+	return s.unregisterTools(func(tool string) bool { return tool == name }) > 0
 }
 
-// RegisterPrompt registers a prompt
-func (s *Server) RegisterPrompt(prompt Prompt) {
-	s.prompts = append(s.prompts, prompt)
+// UnregisterToolsWithPrefix removes every tool whose name starts with
+// prefix, such as all tools of one backend ("portainer_"), and returns how
+// many were removed
+func (s *Server) UnregisterToolsWithPrefix(prefix string) int {
+	return s.unregisterTools(func(tool string) bool { return strings.HasPrefix(tool, prefix) })
 }
 
-// Run starts the MCP server (stdio transport)
+func (s *Server) unregisterTools(match func(name string) bool) int {
+	s.mu.Lock()
+	tools := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		if !match(t.Name) {
+			tools = append(tools, t)
+			continue
+		}
+		delete(s.toolHandlers, t.Name)
+		delete(s.toolAttributes, t.Name)
+		s.forgetItem(listTools, t.Name)
+		s.dropCompletions(ToolRef(t.Name))
+	}
+	removed := len(s.tools) - len(tools)
+	s.tools = tools
+	s.mu.Unlock()
+
+	if removed > 0 {
+		metrics.RecordToolsRegistered(len(tools))
+		s.notifyListChanged("notifications/tools/list_changed")
+	}
+	return removed
+}
+
+// UnregisterResource removes the resource with the given URI, or the
+// resource template written exactly as uri, along with the template's
+// watcher and completions. It reports whether anything was removed.
+func (s *Server) UnregisterResource(uri string) bool {
+	return s.unregisterResources(func(u string) bool { return u == uri }) > 0
+}
+
+// UnregisterResourcesWithPrefix removes every resource and resource
+// template whose URI starts with prefix, such as all resources of one
+// backend ("portainer://"), and returns how many were removed
+func (s *Server) UnregisterResourcesWithPrefix(prefix string) int {
+	return s.unregisterResources(func(u string) bool { return strings.HasPrefix(u, prefix) })
+}
+
+func (s *Server) unregisterResources(match func(uri string) bool) int {
+	s.mu.Lock()
+	resources := make([]Resource, 0, len(s.resources))
+	for _, r := range s.resources {
+		if !match(r.URI) {
+			resources = append(resources, r)
+			continue
+		}
+		delete(s.resourceHandlers, r.URI)
+		s.forgetItem(listResources, r.URI)
+		s.dropCompletions(ResourceRef(r.URI))
+	}
+
+	templates := make([]resourceTemplate, 0, len(s.resourceTemplates))
+	for _, t := range s.resourceTemplates {
+		if !match(t.URITemplate) {
+			templates = append(templates, t)
+			continue
+		}
+		s.forgetItem(listTemplates, t.URITemplate)
+		s.dropCompletions(ResourceRef(t.URITemplate))
+	}
+
+	watchers := make([]*resourceWatcher, 0, len(s.watchers))
+	removedWatchers := make(map[*resourceWatcher]bool)
+	for _, w := range s.watchers {
+		if match(w.uriTemplate.String()) {
+			removedWatchers[w] = true
+			continue
+		}
+		watchers = append(watchers, w)
+	}
+
+	removed := len(s.resources) - len(resources) + len(s.resourceTemplates) - len(templates)
+	s.resources, s.resourceTemplates, s.watchers = resources, templates, watchers
+	s.mu.Unlock()
+
+	if len(removedWatchers) > 0 {
+		s.dropWatcherSubscriptions(removedWatchers)
+	}
+
+	if removed > 0 {
+		metrics.RecordResourcesRegistered(len(resources))
+		s.notifyListChanged("notifications/resources/list_changed")
+	}
+	return removed
+}
+
+// RegisterPrompt registers a prompt with the handler that renders it,
+// replacing any prompt of the same name
+func (s *Server) RegisterPrompt(prompt Prompt, handler PromptHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prompts := make([]Prompt, 0, len(s.prompts)+1)
+	for _, p := range s.prompts {
+		if p.Name != prompt.Name {
+			prompts = append(prompts, p)
+		}
+	}
+	s.prompts = append(prompts, prompt)
+	s.promptHandlers[prompt.Name] = handler
+	s.numberItem(listPrompts, prompt.Name)
+}
+
+// Run starts the MCP server (stdio transport). Requests are handled
+// concurrently, up to the max-in-flight limit, and responses are written as
+// they complete; notifications are handled in arrival order.
 func (s *Server) Run(ctx context.Context) error {
-	s.logger.Println("MCP Server starting...")
+	s.logger.Info("MCP server starting", "transport", "stdio")
 
+	transport := &stdioTransport{out: s.output}
+	sess := NewSession(transport)
+	s.beginSession(sess)
 	scanner := bufio.NewScanner(s.input)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	// Wait for in-flight requests so their responses are not lost on exit.
+	// The session ends first, so that requests waiting for the client fail
+	// instead of timing out.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer s.endSession(sess)
+	slots := make(chan struct{}, s.maxInFlight)
 
 	for {
 		select {
 		case <-ctx.Done():
-			s.logger.Println("Context cancelled, shutting down")
+			s.logger.Info("Context cancelled, shutting down")
 			return ctx.Err()
 		default:
 			if !scanner.Scan() {
@@ -89,7 +396,7 @@ func (s *Server) Run(ctx context.Context) error {
 				// EOF reached
 				if s.isDockerMode() {
 					// In Docker, block waiting for shutdown signal
-					s.logger.Println("Stdin EOF, waiting for shutdown signal...")
+					s.logger.Info("Stdin EOF, waiting for shutdown signal")
 					<-ctx.Done()
 					return nil
 				}
@@ -99,17 +406,60 @@ func (s *Server) Run(ctx context.Context) error {
 
 			line := scanner.Bytes()
 
+			// Batches are decoded up front since the scanner reuses its buffer
+			if IsBatch(line) {
+				batch := append([]byte(nil), line...)
+
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					s.logger.Info("Context cancelled, shutting down")
+					return ctx.Err()
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+
+					if out := s.DispatchBatch(ctx, sess, batch); out != nil {
+						s.reply(ctx, sess, out)
+					}
+				}()
+				continue
+			}
+
 			// Parse JSON-RPC request
 			var req JSONRPCRequest
 			if err := json.Unmarshal(line, &req); err != nil {
-				s.sendError(nil, -32700, "Parse error", err.Error())
+				s.reply(ctx, sess, errorResponse(nil, ParseError, "Parse error", err.Error()))
+				continue
+			}
+
+			// Notifications (e.g. cancellations) and responses to server
+			// requests must never wait behind slow requests, which may be
+			// waiting for those responses, so they are handled inline
+			if req.ID == nil || req.Method == "" {
+				s.Dispatch(ctx, sess, &req)
 				continue
 			}
 
-			// Handle request
-			if err := s.handleRequest(ctx, &req); err != nil {
-				s.logger.Printf("Error handling request: %v", err)
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				s.logger.Info("Context cancelled, shutting down")
+				return ctx.Err()
 			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				if resp := s.Dispatch(ctx, sess, &req); resp != nil {
+					s.reply(ctx, sess, resp)
+				}
+			}()
 		}
 	}
 }
@@ -120,59 +470,189 @@ func (s *Server) isDockerMode() bool {
 	return err == nil
 }
 
-func (s *Server) handleRequest(ctx context.Context, req *JSONRPCRequest) error {
+// reply sends a response, or a batch of responses, through the session's transport
+func (s *Server) reply(ctx context.Context, sess *Session, msg interface{}) {
+	if err := sess.transport.Send(ctx, msg); err != nil {
+		s.logger.Error("Failed to send response", "error", err)
+		return
+	}
+
+	switch v := msg.(type) {
+	case *JSONRPCResponse:
+		s.logger.Debug("Sent response", "id", v.ID)
+	case []*JSONRPCResponse:
+		s.logger.Debug("Sent batch response", "responses", len(v))
+	}
+}
+
+// Dispatch handles a single JSON-RPC message received on a session and
+// returns the response to send back, or nil when the message is a
+// notification. Every transport routes requests through here so that
+// semantics, error codes and metrics are identical across transports.
+func (s *Server) Dispatch(ctx context.Context, sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
 	startTime := time.Now()
-	s.logger.Printf("Received: %s (id=%v)", req.Method, req.ID)
 
-	var err error
-	var errCode string
+	// Handlers may send requests back to the client through ctx, and
+	// records logged with it are forwarded to that client
+	ctx = withScope(ctx, s, sess)
+	s.logger.DebugContext(ctx, "Received request", "method", req.Method, "id", req.ID)
+
+	var resp *JSONRPCResponse
+
+	switch {
+	case req.JSONRPC != "2.0":
+		resp = errorResponse(req.ID, InvalidRequest, "Invalid Request", "jsonrpc must be \"2.0\"")
+	case req.Method == "" && req.ID != nil && (req.Result != nil || req.Error != nil):
+		// A client's response to a server-initiated request
+		if !sess.deliverResponse(req) {
+			s.logger.DebugContext(ctx, "Dropped response to unknown request", "id", req.ID)
+		}
+		return nil
+	case req.Method == "":
+		resp = errorResponse(req.ID, InvalidRequest, "Invalid Request", "method is required")
+	case req.ID == nil:
+		resp = s.handleMethod(ctx, sess, req)
+	default:
+		reqCtx, finish, err := sess.beginRequest(ctx, req.ID)
+		if err != nil {
+			resp = errorResponse(req.ID, InvalidRequest, "Invalid Request", err.Error())
+			break
+		}
+
+		// Route progress reports to the client when it asked for them
+		var params struct {
+			Meta *RequestMeta `json:"_meta"`
+		}
+		if decodeParams(req, &params) == nil && params.Meta != nil && params.Meta.ProgressToken != nil {
+			reqCtx = withProgress(reqCtx, sess, params.Meta.ProgressToken)
+		}
+
+		metrics.InFlightRequests.WithLabelValues(sess.transport.Name()).Inc()
+		resp = s.handleMethod(reqCtx, sess, req)
+		metrics.InFlightRequests.WithLabelValues(sess.transport.Name()).Dec()
+
+		// The client has abandoned a cancelled request, so it gets no response
+		if finish() {
+			s.logger.Info("Request cancelled by client", "method", req.Method, "id", req.ID)
+			metrics.RecordRPCRequest(req.Method, sess.transport.Name(), time.Since(startTime), "cancelled")
+			return nil
+		}
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	// Record metrics
+	duration := time.Since(startTime)
+	errCode := ""
+	if resp.Error != nil {
+		errCode = strconv.Itoa(resp.Error.Code)
+	}
+	metrics.RecordRPCRequest(req.Method, sess.transport.Name(), duration, errCode)
+
+	// Notifications never get a response, even when they fail
+	if req.ID == nil {
+		return nil
+	}
+	return resp
+}
+
+// handleMethod routes a request to its method handler
+func (s *Server) handleMethod(ctx context.Context, sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
+	if req.Method != "initialize" && req.Method != "ping" && !sess.isInitialized() {
+		return errorResponse(req.ID, InvalidRequest, "Session not initialized",
+			"send initialize before "+req.Method)
+	}
 
 	switch req.Method {
 	case "initialize":
-		err = s.handleInitialize(req)
-	case "initialized":
+		return s.handleInitialize(sess, req)
+	case "initialized", "notifications/initialized":
 		// Notification, no response needed
-		s.logger.Println("Client initialized")
+		sess.setReady()
+		s.logger.InfoContext(ctx, "Client initialized")
+		return nil
+	case "notifications/cancelled":
+		s.handleCancelled(sess, req)
 		return nil
 	case "tools/list":
-		err = s.handleListTools(req)
+		return s.handleListTools(ctx, sess, req)
 	case "tools/call":
-		err = s.handleCallTool(ctx, req)
+		start := time.Now()
+		resp := s.handleCallTool(ctx, req)
+		s.auditToolCall(ctx, sess, req, start, resp)
+		adaptToolResult(sess, resp)
+		return resp
 	case "resources/list":
-		err = s.handleListResources(req)
+		return s.handleListResources(ctx, req)
 	case "resources/read":
-		err = s.handleReadResource(ctx, req)
+		return s.handleReadResource(ctx, req)
+	case "resources/templates/list":
+		return s.handleListResourceTemplates(ctx, req)
+	case "resources/subscribe":
+		return s.handleSubscribe(ctx, sess, req)
+	case "resources/unsubscribe":
+		return s.handleUnsubscribe(sess, req)
 	case "prompts/list":
-		err = s.handleListPrompts(req)
+		return s.handleListPrompts(ctx, req)
 	case "prompts/get":
-		err = s.handleGetPrompt(req)
+		resp := s.handleGetPrompt(ctx, req)
+		adaptPromptResult(sess, resp)
+		return resp
+	case "logging/setLevel":
+		return s.handleSetLevel(sess, req)
+	case "completion/complete":
+		if sess.ProtocolVersion() < versionCompletions {
+			return errorResponse(req.ID, MethodNotFound, "Method not found", req.Method)
+		}
+		return s.handleComplete(ctx, req)
 	case "ping":
-		err = s.sendResult(req.ID, map[string]interface{}{})
+		return resultResponse(req.ID, map[string]interface{}{})
 	default:
-		errCode = "-32601"
-		err = s.sendError(req.ID, -32601, "Method not found", req.Method)
+		return errorResponse(req.ID, MethodNotFound, "Method not found", req.Method)
 	}
+}
 
-	// Record metrics
-	duration := time.Since(startTime)
-	if err != nil && errCode == "" {
-		errCode = "unknown"
+func (s *Server) handleCancelled(sess *Session, req *JSONRPCRequest) {
+	var params CancelledNotification
+	if err := decodeParams(req, &params); err != nil {
+		s.logger.Warn("Invalid cancellation", "error", err)
+		return
 	}
-	metrics.RecordRPCRequest(req.Method, "stdio", duration, errCode)
 
-	return err
+	// Unknown or already finished requests are ignored, as the spec requires
+	if sess.cancelRequest(params.RequestID) {
+		s.logger.Info("Cancelling request", "id", params.RequestID, "reason", params.Reason)
+	}
 }
 
-func (s *Server) handleInitialize(req *JSONRPCRequest) error {
+func (s *Server) handleInitialize(sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
+	var params InitializeRequest
+	if err := decodeParams(req, &params); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	version := s.negotiateVersion(params.ProtocolVersion)
+	if err := sess.initialize(version, params.ClientInfo, params.Capabilities); err != nil {
+		return errorResponse(req.ID, InvalidRequest, "Invalid Request", err.Error())
+	}
+	s.logger.Info("Client connected", "client", params.ClientInfo.Name, "clientVersion", params.ClientInfo.Version,
+		"requestedProtocol", params.ProtocolVersion, "protocol", version)
+
+	s.mu.RLock()
+	subscribe, completions := len(s.watchers) > 0, len(s.completers) > 0
+	s.mu.RUnlock()
+
 	result := InitializeResult{
-		ProtocolVersion: "2025-11-25",
+		ProtocolVersion: version,
 		Capabilities: ServerCapabilities{
 			Tools: &ToolsCapability{
-				ListChanged: false,
+				ListChanged: true,
 			},
 			Resources: &ResourcesCapability{
-				Subscribe:   false,
-				ListChanged: false,
+				Subscribe:   subscribe,
+				ListChanged: true,
 			},
 			Prompts: &PromptsCapability{
 				ListChanged: false,
@@ -181,148 +661,353 @@ func (s *Server) handleInitialize(req *JSONRPCRequest) error {
 		},
 		ServerInfo: s.serverInfo,
 	}
+	if completions && version >= versionCompletions {
+		result.Capabilities.Completions = &CompletionsCapability{}
+	}
 
-	return s.sendResult(req.ID, result)
+	return resultResponse(req.ID, result)
 }
 
-func (s *Server) handleListTools(req *JSONRPCRequest) error {
+func (s *Server) handleListTools(ctx context.Context, sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
+	cursor, errResp := listCursor(req)
+	if errResp != nil {
+		return errResp
+	}
+	tools, next, err := s.ListTools(ctx, cursor)
+	if err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
 	result := ListToolsResult{
-		Tools: s.tools,
+		Tools:      adaptTools(sess, tools),
+		NextCursor: next,
 	}
-	return s.sendResult(req.ID, result)
+	return resultResponse(req.ID, result)
 }
 
-func (s *Server) handleCallTool(ctx context.Context, req *JSONRPCRequest) error {
+func (s *Server) handleCallTool(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	var params CallToolRequest
-	paramsBytes, _ := json.Marshal(req.Params)
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		return s.sendError(req.ID, -32602, "Invalid params", err.Error())
+	if err := decodeParams(req, &params); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
 	}
 
+	s.mu.RLock()
 	handler, ok := s.toolHandlers[params.Name]
+	tool := s.findTool(params.Name)
+	attributes := s.toolAttributes[params.Name]
+	policy := s.toolPolicy
+	limiter := s.toolCallLimiter
+	s.mu.RUnlock()
 	if !ok {
-		return s.sendError(req.ID, -32602, "Tool not found", params.Name)
+		return errorResponse(req.ID, InvalidParams, "Tool not found", params.Name)
+	}
+	if tool != nil && !allowTool(ctx, *tool) {
+		s.logger.WarnContext(ctx, "Refused tool not allowed for caller", "tool", params.Name)
+		return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+params.Name)
+	}
+	if tool != nil && limiter != nil {
+		if err := limiter.LimitToolCall(ctx, ToolCall{Tool: *tool, Arguments: params.Arguments}); err != nil {
+			s.logger.WarnContext(ctx, "Tool call rate limited", "tool", params.Name, "error", err)
+			if resp, ok := rateLimitResponse(req.ID, err); ok {
+				return resp
+			}
+			return errorResponse(req.ID, InternalError, "Internal error", err.Error())
+		}
+	}
+
+	// Apply coercions and defaults, then reject arguments that do not match
+	// the tool's input schema
+	if tool != nil {
+		params.Arguments = tool.InputSchema.coerce(params.Arguments)
+		if err := tool.InputSchema.Validate(params.Arguments); err != nil {
+			return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+		}
+
+		if tool.IsDestructive() && !s.allowDestructive {
+			s.logger.WarnContext(ctx, "Refused destructive tool", "tool", params.Name)
+			return resultResponse(req.ID, CallToolResult{
+				Content: []Content{TextContent(fmt.Sprintf(
+					"Error: %s is a destructive tool and destructive tools are disabled on this server", params.Name))},
+				IsError: true,
+			})
+		}
+
+		if policy != nil {
+			call := ToolCall{Tool: *tool, Arguments: params.Arguments, attributes: attributes}
+			if err := policy.CheckToolCall(ctx, call); err != nil {
+				s.logger.WarnContext(ctx, "Tool call denied by policy", "tool", params.Name, "denial", err)
+				return resultResponse(req.ID, CallToolResult{
+					Content: []Content{TextContent(fmt.Sprintf("Error: %s was not run: %v", params.Name, err))},
+					IsError: true,
+				})
+			}
+		}
+
+		if tool.IsDestructive() && s.confirmDestructive && CanElicit(ctx) {
+			confirmed, err := confirmToolCall(ctx, tool, params.Arguments)
+			if err != nil || !confirmed {
+				reason := "the user did not confirm it"
+				if err != nil {
+					reason = fmt.Sprintf("confirmation failed: %v", err)
+				}
+				s.logger.InfoContext(ctx, "Destructive tool not confirmed", "tool", params.Name, "reason", reason)
+				return resultResponse(req.ID, CallToolResult{
+					Content: []Content{TextContent(fmt.Sprintf("Error: %s was not run because %s", params.Name, reason))},
+					IsError: true,
+				})
+			}
+		}
 	}
 
-	// Execute tool
+	// Execute tool. Backends over their concurrency cap answer with a rate
+	// limit error rather than a failed result, so that clients retry.
 	result, err := handler(ctx, params.Arguments)
+	if resp, ok := rateLimitResponse(req.ID, err); ok {
+		s.logger.WarnContext(ctx, "Tool call rate limited", "tool", params.Name, "error", err)
+		return resp
+	}
 	if err != nil {
-		return s.sendResult(req.ID, CallToolResult{
-			Content: []Content{{
-				Type: "text",
-				Text: fmt.Sprintf("Error: %v", err),
-			}},
+		return resultResponse(req.ID, CallToolResult{
+			Content: []Content{TextContent(fmt.Sprintf("Error: %v", err))},
 			IsError: true,
 		})
 	}
 
-	// Convert result to string
-	var text string
+	// Handlers may build the content themselves, e.g. to return images or
+	// embedded resources; anything else is rendered as text
+	var callResult CallToolResult
 	switch v := result.(type) {
+	case *CallToolResult:
+		return resultResponse(req.ID, v)
+	case CallToolResult:
+		return resultResponse(req.ID, v)
+	case []Content:
+		callResult.Content = v
+	case Content:
+		callResult.Content = []Content{v}
 	case string:
-		text = v
+		callResult.Content = []Content{TextContent(v)}
 	default:
 		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
-		text = string(jsonBytes)
+		callResult.Content = []Content{TextContent(string(jsonBytes))}
+	}
+
+	// Tools with an output schema also return their result as data
+	if tool != nil && tool.OutputSchema != nil {
+		structured, err := toStructuredContent(result)
+		if err != nil {
+			return errorResponse(req.ID, InternalError, "Internal error",
+				fmt.Sprintf("tool %s returned invalid structured content: %v", params.Name, err))
+		}
+		callResult.StructuredContent = structured
 	}
 
-	return s.sendResult(req.ID, CallToolResult{
-		Content: []Content{{
-			Type: "text",
-			Text: text,
-		}},
-		IsError: false,
-	})
+	return resultResponse(req.ID, callResult)
 }
 
-func (s *Server) handleListResources(req *JSONRPCRequest) error {
+func (s *Server) handleListResources(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	cursor, errResp := listCursor(req)
+	if errResp != nil {
+		return errResp
+	}
+	s.mu.RLock()
+	resources := visible(ctx, s.resources, func(ctx context.Context, r Resource) bool { return allowResource(ctx, r.URI) })
+	page, next, err := paginate(s, resources, cursor, listResources, func(r Resource) string { return r.URI })
+	s.mu.RUnlock()
+	if err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
 	result := ListResourcesResult{
-		Resources: s.resources,
+		Resources:  page,
+		NextCursor: next,
 	}
-	return s.sendResult(req.ID, result)
+	return resultResponse(req.ID, result)
 }
 
-func (s *Server) handleReadResource(ctx context.Context, req *JSONRPCRequest) error {
+func (s *Server) handleReadResource(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	var params ReadResourceRequest
-	paramsBytes, _ := json.Marshal(req.Params)
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		return s.sendError(req.ID, -32602, "Invalid params", err.Error())
+	if err := decodeParams(req, &params); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	contents, err := s.ReadResource(ctx, params.URI)
+	if errors.Is(err, ErrResourceNotFound) {
+		return errorResponse(req.ID, InvalidParams, "Resource not found", params.URI)
+	}
+	if errors.Is(err, ErrForbidden) {
+		return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+params.URI)
+	}
+	if resp, ok := rateLimitResponse(req.ID, err); ok {
+		return resp
+	}
+	if err != nil {
+		return errorResponse(req.ID, InternalError, "Internal error", err.Error())
+	}
+
+	result := ReadResourceResult{
+		Contents: []ResourceContents{*contents},
+	}
+
+	return resultResponse(req.ID, result)
+}
+
+// ReadResource reads a resource by URI, trying exact resources before
+// resource templates. It returns ErrResourceNotFound if nothing matches,
+// and ErrForbidden if the caller in ctx may not read it.
+func (s *Server) ReadResource(ctx context.Context, uri string) (*ResourceContents, error) {
+	if !allowResource(ctx, uri) {
+		return nil, ErrForbidden
 	}
 
-	handler, ok := s.resourceHandlers[params.URI]
+	s.mu.RLock()
+	handler, ok := s.resourceHandlers[uri]
+	if !ok {
+		handler, ok = s.matchResourceTemplate(uri)
+	}
+	s.mu.RUnlock()
 	if !ok {
-		return s.sendError(req.ID, -32602, "Resource not found", params.URI)
+		return nil, ErrResourceNotFound
 	}
 
-	content, mimeType, err := handler(ctx, params.URI)
+	content, mimeType, err := handler(ctx, uri)
 	if err != nil {
-		return s.sendError(req.ID, -32603, "Internal error", err.Error())
+		return nil, err
 	}
 
-	result := ReadResourceResult{
-		Contents: []ResourceContents{{
-			URI:      params.URI,
-			MimeType: mimeType,
-			Text:     content,
-		}},
+	return &ResourceContents{
+		URI:      uri,
+		MimeType: mimeType,
+		Text:     content,
+	}, nil
+}
+
+func (s *Server) handleListResourceTemplates(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	cursor, errResp := listCursor(req)
+	if errResp != nil {
+		return errResp
+	}
+	s.mu.RLock()
+	templates := visible(ctx, s.resourceTemplates, func(ctx context.Context, t resourceTemplate) bool { return allowResource(ctx, t.URITemplate) })
+	page, next, err := paginate(s, templates, cursor, listTemplates, func(t resourceTemplate) string { return t.URITemplate })
+	s.mu.RUnlock()
+	if err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
+	result := ListResourceTemplatesResult{
+		ResourceTemplates: make([]ResourceTemplate, 0, len(page)),
+		NextCursor:        next,
+	}
+	for _, t := range page {
+		result.ResourceTemplates = append(result.ResourceTemplates, t.ResourceTemplate)
 	}
+	return resultResponse(req.ID, result)
+}
 
-	return s.sendResult(req.ID, result)
+// matchResourceTemplate returns a handler for uri from the first matching
+// resource template. The caller must hold mu.
+func (s *Server) matchResourceTemplate(uri string) (ResourceHandler, bool) {
+	for _, t := range s.resourceTemplates {
+		params, ok := t.uriTemplate.Match(uri)
+		if !ok {
+			continue
+		}
+		handler := t.handler
+		return func(ctx context.Context, uri string) (string, string, error) {
+			return handler(ctx, uri, params)
+		}, true
+	}
+	return nil, false
 }
 
-func (s *Server) handleListPrompts(req *JSONRPCRequest) error {
+func (s *Server) handleListPrompts(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	cursor, errResp := listCursor(req)
+	if errResp != nil {
+		return errResp
+	}
+	s.mu.RLock()
+	prompts := visible(ctx, s.prompts, allowPrompt)
+	page, next, err := paginate(s, prompts, cursor, listPrompts, func(p Prompt) string { return p.Name })
+	s.mu.RUnlock()
+	if err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
+	}
+
 	result := ListPromptsResult{
-		Prompts: s.prompts,
+		Prompts:    page,
+		NextCursor: next,
 	}
-	return s.sendResult(req.ID, result)
+	return resultResponse(req.ID, result)
 }
 
-func (s *Server) handleGetPrompt(req *JSONRPCRequest) error {
+func (s *Server) handleGetPrompt(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	var params GetPromptRequest
-	paramsBytes, _ := json.Marshal(req.Params)
-	if err := json.Unmarshal(paramsBytes, &params); err != nil {
-		return s.sendError(req.ID, -32602, "Invalid params", err.Error())
+	if err := decodeParams(req, &params); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", err.Error())
 	}
 
 	// Find prompt
-	var prompt *Prompt
-	for i := range s.prompts {
-		if s.prompts[i].Name == params.Name {
-			prompt = &s.prompts[i]
-			break
-		}
-	}
+	s.mu.RLock()
+	prompt := s.findPrompt(params.Name)
+	handler := s.promptHandlers[params.Name]
+	s.mu.RUnlock()
 
 	if prompt == nil {
-		return s.sendError(req.ID, -32602, "Prompt not found", params.Name)
+		return errorResponse(req.ID, InvalidParams, "Prompt not found", params.Name)
+	}
+	if !allowPrompt(ctx, *prompt) {
+		return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+params.Name)
 	}
 
-	// For now, return a simple prompt - extend later for actual templates
-	result := GetPromptResult{
-		Description: prompt.Description,
-		Messages: []PromptMessage{{
-			Role: "user",
-			Content: PromptContent{
-				Type: "text",
-				Text: fmt.Sprintf("Execute prompt: %s", params.Name),
-			},
-		}},
+	if err := prompt.checkArguments(params.Arguments); err != nil {
+		return errorResponse(req.ID, InvalidParams, "Invalid arguments", err.Error())
+	}
+
+	arguments := params.Arguments
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+
+	result, err := handler(ctx, arguments)
+	if err != nil {
+		return errorResponse(req.ID, InternalError, "Internal error", err.Error())
+	}
+	if result.Description == "" {
+		result.Description = prompt.Description
 	}
 
-	return s.sendResult(req.ID, result)
+	return resultResponse(req.ID, result)
+}
+
+// checkArguments rejects arguments the prompt does not declare and missing
+// required ones
+func (p *Prompt) checkArguments(arguments map[string]string) error {
+	declared := make(map[string]bool, len(p.Arguments))
+	for _, arg := range p.Arguments {
+		declared[arg.Name] = true
+		if arg.Required && arguments[arg.Name] == "" {
+			return fmt.Errorf("missing required argument %q", arg.Name)
+		}
+	}
+	for name := range arguments {
+		if !declared[name] {
+			return fmt.Errorf("unknown argument %q", name)
+		}
+	}
+	return nil
 }
 
-func (s *Server) sendResult(id interface{}, result interface{}) error {
-	resp := JSONRPCResponse{
+func resultResponse(id interface{}, result interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 		Result:  result,
 	}
-	return s.sendResponse(resp)
 }
 
-func (s *Server) sendError(id interface{}, code int, message string, data interface{}) error {
-	resp := JSONRPCResponse{
+func errorResponse(id interface{}, code int, message string, data interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 		Error: &JSONRPCError{
@@ -331,120 +1016,61 @@ func (s *Server) sendError(id interface{}, code int, message string, data interf
 			Data:    data,
 		},
 	}
-	return s.sendResponse(resp)
 }
 
-func (s *Server) sendResponse(resp JSONRPCResponse) error {
-	data, err := json.Marshal(resp)
-	if err != nil {
-		return fmt.Errorf("marshal error: %w", err)
+func newNotification(method string, params interface{}) *JSONRPCNotification {
+	return &JSONRPCNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+}
+
+// findTool returns the registered tool with the given name, or nil. The
+// caller must hold mu.
+func (s *Server) findTool(name string) *Tool {
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			return &s.tools[i]
+		}
 	}
+	return nil
+}
 
-	data = append(data, '\n')
+// toStructuredContent converts a tool result into the JSON object sent as
+// structuredContent
+func toStructuredContent(result interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := s.output.Write(data); err != nil {
-		return fmt.Errorf("write error: %w", err)
+	var structured map[string]interface{}
+	if err := json.Unmarshal(data, &structured); err != nil {
+		return nil, fmt.Errorf("result is not a JSON object: %w", err)
 	}
+	return structured, nil
+}
 
-	s.logger.Printf("Sent response (id=%v)", resp.ID)
-	return nil
+// decodeParams unmarshals request params into v
+func decodeParams(req *JSONRPCRequest, v interface{}) error {
+	paramsBytes, err := json.Marshal(req.Params)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(paramsBytes, v)
 }
 
 // GetTools returns the list of registered tools
 func (s *Server) GetTools() []Tool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.tools
 }
 
 // GetResources returns the list of registered resources
 func (s *Server) GetResources() []Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.resources
 }
-
-// HandleHTTPRequest handles an HTTP JSON-RPC request
-func (s *Server) HandleHTTPRequest(ctx context.Context, req *JSONRPCRequest) (interface{}, error) {
-	switch req.Method {
-	case "tools/list":
-		return map[string]interface{}{
-			"tools": s.tools,
-		}, nil
-
-	case "tools/call":
-		var params struct {
-			Name      string                 `json:"name"`
-			Arguments map[string]interface{} `json:"arguments"`
-		}
-
-		// Convert params interface{} to struct
-		paramsBytes, err := json.Marshal(req.Params)
-		if err != nil {
-			return nil, fmt.Errorf("invalid params: %w", err)
-		}
-		if err := json.Unmarshal(paramsBytes, &params); err != nil {
-			return nil, fmt.Errorf("invalid params: %w", err)
-		}
-
-		handler, exists := s.toolHandlers[params.Name]
-		if !exists {
-			return nil, fmt.Errorf("tool not found: %s", params.Name)
-		}
-
-		result, err := handler(ctx, params.Arguments)
-		if err != nil {
-			return nil, fmt.Errorf("tool execution failed: %w", err)
-		}
-
-		return map[string]interface{}{
-			"content": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": fmt.Sprintf("%v", result),
-				},
-			},
-		}, nil
-
-	case "resources/list":
-		return map[string]interface{}{
-			"resources": s.resources,
-		}, nil
-
-	case "resources/read":
-		var params struct {
-			URI string `json:"uri"`
-		}
-
-		// Convert params interface{} to struct
-		paramsBytes, err := json.Marshal(req.Params)
-		if err != nil {
-			return nil, fmt.Errorf("invalid params: %w", err)
-		}
-		if err := json.Unmarshal(paramsBytes, &params); err != nil {
-			return nil, fmt.Errorf("invalid params: %w", err)
-		}
-
-		handler, exists := s.resourceHandlers[params.URI]
-		if !exists {
-			return nil, fmt.Errorf("resource not found: %s", params.URI)
-		}
-
-		content, mimeType, err := handler(ctx, params.URI)
-		if err != nil {
-			return nil, fmt.Errorf("resource read failed: %w", err)
-		}
-
-		return map[string]interface{}{
-			"contents": []map[string]interface{}{
-				{
-					"uri":      params.URI,
-					"mimeType": mimeType,
-					"text":     content,
-				},
-			},
-		}, nil
-
-	case "ping":
-		return map[string]interface{}{}, nil
-
-	default:
-		return nil, fmt.Errorf("method not supported via HTTP: %s", req.Method)
-	}
-}
@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Session is the server-side state of one client connection
+type Session struct {
+	transport Transport
+
+	mu       sync.Mutex
+	inflight map[string]*inflightRequest
+
+	// outbound holds the server-initiated requests awaiting a response,
+	// by ID; closed is set once the client has gone
+	outbound       map[string]chan *JSONRPCResponse
+	lastOutboundID int64
+	closed         bool
+
+	// Set by a successful initialize; requests other than initialize and
+	// ping are rejected until then. Stateless sessions start initialized
+	// and accept initialize at any time.
+	stateless       bool
+	initialized     bool
+	protocolVersion string
+	clientInfo      Implementation
+	clientCaps      ClientCapabilities
+
+	// ready is set once the client has sent notifications/initialized,
+	// ending the handshake; only then is it told of list changes
+	ready bool
+
+	// logLevel is the minimum level of log records forwarded to the
+	// client, once it has called logging/setLevel
+	logLevel   slog.Level
+	logEnabled bool
+}
+
+// inflightRequest tracks a request that is still being handled
+type inflightRequest struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// NewSession creates a session bound to a transport
+func NewSession(transport Transport) *Session {
+	return &Session{
+		transport: transport,
+		inflight:  make(map[string]*inflightRequest),
+		outbound:  make(map[string]chan *JSONRPCResponse),
+	}
+}
+
+// Transport returns the transport the session is bound to
+func (sess *Session) Transport() Transport {
+	return sess.transport
+}
+
+// ProtocolVersion returns the protocol version negotiated by initialize
+func (sess *Session) ProtocolVersion() string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.protocolVersion
+}
+
+// ClientCapabilities returns the capabilities the client declared in
+// initialize
+func (sess *Session) ClientCapabilities() ClientCapabilities {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.clientCaps
+}
+
+// ClientInfo returns the name and version the client sent in initialize
+func (sess *Session) ClientInfo() Implementation {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.clientInfo
+}
+
+// isInitialized reports whether initialize has succeeded
+func (sess *Session) isInitialized() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.initialized
+}
+
+// initialize records the outcome of initialize. It fails if the session
+// was already initialized, unless it is stateless.
+func (sess *Session) initialize(version string, info Implementation, caps ClientCapabilities) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.initialized && !sess.stateless {
+		return fmt.Errorf("session is already initialized (protocol %s)", sess.protocolVersion)
+	}
+	sess.initialized = true
+	sess.protocolVersion, sess.clientInfo, sess.clientCaps = version, info, caps
+	return nil
+}
+
+// setReady records that the client has finished the initialize handshake
+func (sess *Session) setReady() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.ready = sess.initialized
+}
+
+// isReady reports whether the client has finished the initialize handshake
+func (sess *Session) isReady() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.ready
+}
+
+// beginRequest registers a request as in flight and returns a context that
+// is cancelled when the client sends notifications/cancelled for it. The
+// returned finish func must be called once the request has been handled; it
+// reports whether the client cancelled the request.
+func (sess *Session) beginRequest(ctx context.Context, id interface{}) (context.Context, func() bool, error) {
+	key := requestKey(id)
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if _, exists := sess.inflight[key]; exists {
+		return nil, nil, fmt.Errorf("request id %s is already in flight", key)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	req := &inflightRequest{cancel: cancel}
+	sess.inflight[key] = req
+
+	finish := func() bool {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+
+		delete(sess.inflight, key)
+		cancel()
+		return req.cancelled
+	}
+
+	return ctx, finish, nil
+}
+
+// cancelRequest cancels an in-flight request, reporting whether it was found
+func (sess *Session) cancelRequest(id interface{}) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	req, ok := sess.inflight[requestKey(id)]
+	if !ok {
+		return false
+	}
+	req.cancelled = true
+	req.cancel()
+	return true
+}
+
+// requestKey normalizes a JSON-RPC ID so that 1 and "1" stay distinct
+func requestKey(id interface{}) string {
+	data, err := json.Marshal(id)
+	if err != nil {
+		return fmt.Sprint(id)
+	}
+	return string(data)
+}
+
+// setLogLevel starts forwarding log records at or above level to the client
+func (sess *Session) setLogLevel(level slog.Level) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.logLevel, sess.logEnabled = level, true
+}
+
+// wantsLog reports whether the client asked for log records at level
+func (sess *Session) wantsLog(level slog.Level) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.logEnabled && level >= sess.logLevel
+}
@@ -0,0 +1,256 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRequestKey(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		same bool
+	}{
+		{float64(1), float64(1), true},
+		{float64(1), "1", false},
+		{"a", "a", true},
+		{"a", "b", false},
+		{float64(1), float64(2), false},
+	}
+
+	for _, tt := range tests {
+		if got := requestKey(tt.a) == requestKey(tt.b); got != tt.same {
+			t.Errorf("requestKey(%#v) == requestKey(%#v) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestBeginRequest(t *testing.T) {
+	sess := NewSession(&stdioTransport{out: io.Discard})
+
+	_, finish1, err := sess.beginRequest(context.Background(), float64(1))
+	if err != nil {
+		t.Fatalf("begin 1: %v", err)
+	}
+	if _, _, err := sess.beginRequest(context.Background(), float64(1)); err == nil {
+		t.Error("duplicate in-flight ID 1 was accepted")
+	}
+	_, finishString, err := sess.beginRequest(context.Background(), "1")
+	if err != nil {
+		t.Errorf(`begin "1" while 1 is in flight: %v`, err)
+	}
+
+	if finish1() {
+		t.Error("request 1 reported as cancelled")
+	}
+	_, finishAgain, err := sess.beginRequest(context.Background(), float64(1))
+	if err != nil {
+		t.Fatalf("begin 1 after it finished: %v", err)
+	}
+	finishAgain()
+	finishString()
+}
+
+func TestCancelRequest(t *testing.T) {
+	sess := NewSession(&stdioTransport{out: io.Discard})
+
+	ctx, finish, err := sess.beginRequest(context.Background(), "job")
+	if err != nil {
+		t.Fatalf("beginRequest: %v", err)
+	}
+	if sess.cancelRequest(float64(7)) {
+		t.Error("cancelling an unknown request succeeded")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("request cancelled by another ID")
+	}
+
+	if !sess.cancelRequest("job") {
+		t.Fatal("cancelling an in-flight request failed")
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("context not cancelled")
+	}
+	if !finish() {
+		t.Error("finish did not report the cancellation")
+	}
+	if sess.cancelRequest("job") {
+		t.Error("cancelling a finished request succeeded")
+	}
+}
+
+// stdioHarness runs a server's stdio transport over pipes
+type stdioHarness struct {
+	t         *testing.T
+	in        *io.PipeWriter
+	responses chan JSONRPCResponse
+	cancel    context.CancelFunc
+	done      chan error
+}
+
+func startStdio(t *testing.T, s *Server) *stdioHarness {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	s.input, s.output = inR, outW
+
+	// Run waits for the context rather than EOF when it detects Docker
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &stdioHarness{t: t, in: inW, responses: make(chan JSONRPCResponse, 16), cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		h.done <- s.Run(ctx)
+		outW.Close()
+	}()
+	go func() {
+		defer close(h.responses)
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			// Skip server-initiated notifications and requests
+			var msg struct {
+				Method string `json:"method"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &msg) == nil && msg.Method != "" {
+				continue
+			}
+			var resp JSONRPCResponse
+			if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+				t.Errorf("invalid response %q: %v", scanner.Text(), err)
+				continue
+			}
+			h.responses <- resp
+		}
+	}()
+	t.Cleanup(h.close)
+	return h
+}
+
+func (h *stdioHarness) send(msg string) {
+	h.t.Helper()
+	if _, err := io.WriteString(h.in, msg+"\n"); err != nil {
+		h.t.Fatalf("write %s: %v", msg, err)
+	}
+}
+
+// next returns the next response
+func (h *stdioHarness) next() JSONRPCResponse {
+	h.t.Helper()
+	select {
+	case resp, ok := <-h.responses:
+		if !ok {
+			h.t.Fatal("server closed its output")
+		}
+		return resp
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("timed out waiting for a response")
+		return JSONRPCResponse{}
+	}
+}
+
+// none fails if a response arrives within d
+func (h *stdioHarness) none(d time.Duration) {
+	h.t.Helper()
+	select {
+	case resp, ok := <-h.responses:
+		if ok {
+			h.t.Errorf("unexpected response %+v", resp)
+		}
+	case <-time.After(d):
+	}
+}
+
+// close ends the input and waits for Run to return
+func (h *stdioHarness) close() {
+	h.in.Close()
+	h.cancel()
+	select {
+	case <-h.done:
+	case <-time.After(5 * time.Second):
+		h.t.Error("Run did not return after its input closed")
+	}
+}
+
+// newBlockingServer returns a server with an echo tool and a block tool
+// that runs until it is cancelled
+func newBlockingServer() *Server {
+	s := NewServer("test", "1.0.0", "2025-11-25")
+	s.RegisterTool(Tool{Name: "echo", InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnly("Echo")},
+		func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+			return "hello", nil
+		})
+	s.RegisterTool(Tool{Name: "block", InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnly("Block")},
+		func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	return s
+}
+
+const initializeRequest = `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-11-25","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+
+func initializeStdio(h *stdioHarness) {
+	h.t.Helper()
+	h.send(initializeRequest)
+	if resp := h.next(); resp.Error != nil {
+		h.t.Fatalf("initialize: %+v", resp.Error)
+	}
+	h.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+}
+
+// initializedSession returns a session on which initialization has
+// completed
+func initializedSession(t *testing.T, s *Server) *Session {
+	t.Helper()
+	sess := NewSession(&stdioTransport{out: io.Discard})
+	for _, msg := range []string{initializeRequest, `{"jsonrpc":"2.0","method":"notifications/initialized"}`} {
+		var req JSONRPCRequest
+		if err := json.Unmarshal([]byte(msg), &req); err != nil {
+			t.Fatalf("decode %s: %v", msg, err)
+		}
+		if resp := s.Dispatch(context.Background(), sess, &req); resp != nil && resp.Error != nil {
+			t.Fatalf("%s: %+v", req.Method, resp.Error)
+		}
+	}
+	return sess
+}
+
+func TestStdioConcurrentCancellation(t *testing.T) {
+	h := startStdio(t, newBlockingServer())
+	initializeStdio(h)
+
+	// A slow request does not hold up the next one
+	h.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	h.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo"}}`)
+	if resp := h.next(); resp.ID != float64(2) || resp.Error != nil {
+		t.Fatalf("got %+v, want the result of request 2", resp)
+	}
+
+	// An ID already in flight is refused; the same ID as a string is not
+	h.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`)
+	resp := h.next()
+	if resp.ID != float64(1) || resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Fatalf("got %+v, want an Invalid Request error for the duplicate ID 1", resp)
+	}
+	h.send(`{"jsonrpc":"2.0","id":"1","method":"tools/call","params":{"name":"echo"}}`)
+	if resp := h.next(); resp.ID != "1" || resp.Error != nil {
+		t.Fatalf(`got %+v, want the result of request "1"`, resp)
+	}
+
+	// Cancelling by the string ID leaves request 1 running
+	h.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"1"}}`)
+	h.none(50 * time.Millisecond)
+
+	// A cancelled request gets no response at all
+	h.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"test"}}`)
+	h.none(100 * time.Millisecond)
+
+	// Its ID may be used again
+	h.send(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	if resp := h.next(); resp.ID != float64(1) || resp.Error != nil {
+		t.Fatalf("got %+v, want the ping result", resp)
+	}
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// SSEWriter writes JSON-RPC messages to an HTTP response as server-sent
+// events. It is safe for concurrent use, so progress notifications and the
+// final response of a request can share one stream.
+type SSEWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// NewSSEWriter starts an event stream on w. It reports false, without
+// writing anything, when the response writer cannot be flushed.
+func NewSSEWriter(w http.ResponseWriter) (*SSEWriter, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &SSEWriter{w: w, flusher: flusher}, true
+}
+
+// Send writes msg as a "message" event
+func (s *SSEWriter) Send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	return s.writeEvent(data)
+}
+
+// writeEvent writes pre-encoded JSON as a "message" event
+func (s *SSEWriter) writeEvent(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := fmt.Fprintf(s.w, "event: message\ndata: %s\n\n", data); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// keepAlive writes an SSE comment so idle proxies keep the stream open
+func (s *SSEWriter) keepAlive() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := io.WriteString(s.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+type requestStreamKey struct{}
+
+// withRequestStream routes messages sent on behalf of a request onto the
+// SSE stream answering that request
+func withRequestStream(ctx context.Context, stream *SSEWriter) context.Context {
+	return context.WithValue(ctx, requestStreamKey{}, stream)
+}
+
+// requestStream returns the SSE stream answering the request in ctx, if any
+func requestStream(ctx context.Context) *SSEWriter {
+	stream, _ := ctx.Value(requestStreamKey{}).(*SSEWriter)
+	return stream
+}
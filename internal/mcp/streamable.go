@@ -0,0 +1,488 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionIDHeader carries the MCP session identifier on Streamable HTTP
+	SessionIDHeader = "Mcp-Session-Id"
+
+	// ProtocolVersionHeader carries the negotiated protocol version on
+	// requests after initialize
+	ProtocolVersionHeader = "Mcp-Protocol-Version"
+
+	// maxMessageSize bounds the size of a single POSTed JSON-RPC message
+	maxMessageSize = 4 << 20
+
+	// sessionIdleTimeout is how long a session may go unused before it is dropped
+	sessionIdleTimeout = 30 * time.Minute
+
+	// maxSessions bounds the live sessions, and maxSessionsPerCaller those
+	// of one caller, so that clients cannot exhaust memory by initializing
+	maxSessions          = 1024
+	maxSessionsPerCaller = 64
+
+	// keepAliveInterval is how often an idle SSE stream receives a comment line
+	keepAliveInterval = 25 * time.Second
+)
+
+// StreamableHTTPHandler implements the MCP Streamable HTTP transport.
+// A single endpoint accepts POST (client messages), GET (server-to-client
+// event stream) and DELETE (session termination). Requests are routed into
+// the same dispatcher as the stdio transport.
+type StreamableHTTPHandler struct {
+	server         *Server
+	allowedOrigins []string
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession is the server-side state of one Streamable HTTP session.
+// It is the Transport of its Session: server-initiated messages are queued
+// for the session's GET stream.
+type httpSession struct {
+	id      string
+	session *Session
+
+	// owner is the identity of the caller that initialized the session;
+	// only that caller may use it
+	owner string
+
+	mu        sync.Mutex
+	lastSeen  time.Time
+	streaming bool
+	closed    bool
+
+	// outbound holds server-initiated messages for the GET stream
+	outbound chan []byte
+}
+
+func (hs *httpSession) Name() string {
+	return "streamable-http"
+}
+
+func (hs *httpSession) persistent() {}
+
+func (hs *httpSession) Send(ctx context.Context, msg interface{}) error {
+	// Messages about a request go on the stream answering that request
+	if stream := requestStream(ctx); stream != nil {
+		return stream.Send(msg)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.closed {
+		return fmt.Errorf("session %s is closed", hs.id)
+	}
+	select {
+	case hs.outbound <- data:
+		return nil
+	default:
+		return fmt.Errorf("session %s outbound queue is full", hs.id)
+	}
+}
+
+// connected implements intermittentTransport. Messages reach the client on
+// the SSE stream answering the request in ctx, or on the GET stream.
+func (hs *httpSession) connected(ctx context.Context) bool {
+	if requestStream(ctx) != nil {
+		return true
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.streaming && !hs.closed
+}
+
+// close ends the session and its GET stream
+func (hs *httpSession) close() {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if !hs.closed {
+		hs.closed = true
+		close(hs.outbound)
+	}
+}
+
+// idle reports whether the session has gone unused for longer than timeout
+func (hs *httpSession) idle(timeout time.Duration) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	return !hs.streaming && time.Since(hs.lastSeen) > timeout
+}
+
+func (hs *httpSession) touch() {
+	hs.mu.Lock()
+	hs.lastSeen = time.Now()
+	hs.mu.Unlock()
+}
+
+// NewStreamableHTTPHandler creates a Streamable HTTP handler for the server.
+// Requests carrying an Origin header not listed in allowedOrigins are rejected.
+func NewStreamableHTTPHandler(server *Server, allowedOrigins []string) *StreamableHTTPHandler {
+	return &StreamableHTTPHandler{
+		server:         server,
+		allowedOrigins: allowedOrigins,
+		sessions:       make(map[string]*httpSession),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Validate Origin to prevent DNS rebinding attacks
+	if origin := r.Header.Get("Origin"); origin != "" && !h.originAllowed(origin) {
+		writeHTTPError(w, http.StatusForbidden, ServerError, "Origin not allowed", origin)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeHTTPError(w, http.StatusMethodNotAllowed, ServerError, "Method not allowed", nil)
+	}
+}
+
+func (h *StreamableHTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
+	// The specification has clients accept both, but responses are plain
+	// JSON unless the client accepts a stream, so either will do
+	accept := r.Header.Get("Accept")
+	if !acceptsAny(accept, "application/json", "text/event-stream") {
+		writeHTTPError(w, http.StatusNotAcceptable, ServerError,
+			"Accept header must include application/json or text/event-stream", accept)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, ParseError, "Parse error", err.Error())
+		return
+	}
+
+	if IsBatch(body) {
+		h.handleBatch(w, r, body)
+		return
+	}
+
+	var req JSONRPCRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeHTTPError(w, http.StatusBadRequest, ParseError, "Parse error", err.Error())
+		return
+	}
+
+	var sess *httpSession
+	if req.Method == "initialize" {
+		sess, err = h.newSession(r.Context())
+		if err != nil {
+			writeHTTPError(w, http.StatusServiceUnavailable, ServerError, "Too many sessions", err.Error())
+			return
+		}
+	} else if sess = h.requestSession(w, r); sess == nil {
+		return
+	}
+
+	// Responses from the client and notifications are acknowledged without
+	// a body. Responses complete the server requests waiting for them.
+	if req.Method == "" || req.ID == nil {
+		h.server.Dispatch(r.Context(), sess.session, &req)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Tool calls may run for a long time, so upgrade them to an SSE stream
+	// when the client supports it. Progress and other messages about the
+	// call are sent on that stream ahead of the response.
+	ctx := r.Context()
+	var stream *SSEWriter
+	if req.Method == "tools/call" && acceptsAny(accept, "text/event-stream") {
+		var ok bool
+		if stream, ok = NewSSEWriter(w); ok {
+			ctx = withRequestStream(ctx, stream)
+		}
+	}
+
+	resp := h.server.Dispatch(ctx, sess.session, &req)
+
+	if stream != nil {
+		if resp != nil {
+			if err := stream.Send(resp); err != nil {
+				h.server.logger.Warn("Failed to write SSE response", "error", err)
+			}
+		}
+		return
+	}
+
+	if resp == nil {
+		// Cancelled by the client, nothing to answer
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if req.Method == "initialize" {
+		if resp.Error != nil {
+			h.closeSession(sess.id)
+		} else {
+			w.Header().Set(SessionIDHeader, sess.id)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleBatch handles a JSON-RPC batch on an existing session. Batches are
+// answered with a JSON array, never a stream.
+func (h *StreamableHTTPHandler) handleBatch(w http.ResponseWriter, r *http.Request, body []byte) {
+	sess := h.requestSession(w, r)
+	if sess == nil {
+		return
+	}
+
+	out := h.server.DispatchBatch(r.Context(), sess.session, body)
+	if out == nil {
+		// Only notifications and responses, nothing to return
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(out)
+}
+
+// requestSession returns the session a request after initialize belongs
+// to, or writes the error and returns nil when it cannot be used
+func (h *StreamableHTTPHandler) requestSession(w http.ResponseWriter, r *http.Request) *httpSession {
+	sess, status := h.lookupSession(r.Context(), r.Header.Get(SessionIDHeader))
+	if sess == nil {
+		writeSessionError(w, status)
+		return nil
+	}
+
+	// Clients older than the header omit it; a version that was not
+	// negotiated is rejected
+	if version := r.Header.Get(ProtocolVersionHeader); version != "" && version != sess.session.ProtocolVersion() {
+		writeHTTPError(w, http.StatusBadRequest, InvalidRequest, "Unsupported protocol version",
+			fmt.Sprintf("%s %q does not match the negotiated version %q", ProtocolVersionHeader, version, sess.session.ProtocolVersion()))
+		return nil
+	}
+	return sess
+}
+
+func (h *StreamableHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsAny(r.Header.Get("Accept"), "text/event-stream") {
+		writeHTTPError(w, http.StatusMethodNotAllowed, ServerError, "GET requires Accept: text/event-stream", nil)
+		return
+	}
+
+	sess, status := h.lookupSession(r.Context(), r.Header.Get(SessionIDHeader))
+	if sess == nil {
+		writeSessionError(w, status)
+		return
+	}
+
+	if _, ok := w.(http.Flusher); !ok {
+		writeHTTPError(w, http.StatusInternalServerError, InternalError, "Streaming not supported", nil)
+		return
+	}
+
+	// Only one standalone stream per session, so messages are never duplicated
+	sess.mu.Lock()
+	if sess.streaming {
+		sess.mu.Unlock()
+		writeHTTPError(w, http.StatusConflict, ServerError, "Stream already open for session", nil)
+		return
+	}
+	sess.streaming = true
+	sess.mu.Unlock()
+
+	defer func() {
+		sess.mu.Lock()
+		sess.streaming = false
+		sess.lastSeen = time.Now()
+		sess.mu.Unlock()
+	}()
+
+	stream, _ := NewSSEWriter(w)
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sess.outbound:
+			if !ok {
+				// Session was terminated
+				return
+			}
+			if err := stream.writeEvent(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := stream.keepAlive(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamableHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	sess, status := h.lookupSession(r.Context(), r.Header.Get(SessionIDHeader))
+	if sess == nil {
+		writeSessionError(w, status)
+		return
+	}
+
+	h.closeSession(sess.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newSession creates and stores a session owned by the caller in ctx,
+// dropping any idle ones. It fails when there are too many sessions.
+func (h *StreamableHTTPHandler) newSession(ctx context.Context) (*httpSession, error) {
+	owner := callerIdentity(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	owned := 0
+	for id, s := range h.sessions {
+		if s.idle(sessionIdleTimeout) {
+			s.close()
+			h.server.endSession(s.session)
+			delete(h.sessions, id)
+			continue
+		}
+		if s.owner == owner {
+			owned++
+		}
+	}
+	if len(h.sessions) >= maxSessions {
+		return nil, fmt.Errorf("the server has %d sessions", len(h.sessions))
+	}
+	if owned >= maxSessionsPerCaller {
+		return nil, fmt.Errorf("the caller has %d sessions; delete unused ones", owned)
+	}
+
+	sess := &httpSession{
+		id:       newSessionID(),
+		owner:    owner,
+		lastSeen: time.Now(),
+		outbound: make(chan []byte, 64),
+	}
+	sess.session = NewSession(sess)
+	h.server.beginSession(sess.session)
+	h.sessions[sess.id] = sess
+
+	return sess, nil
+}
+
+// lookupSession finds the session for an ID, returning the HTTP status to
+// reply with when it cannot be used. Sessions of other callers are not
+// found.
+func (h *StreamableHTTPHandler) lookupSession(ctx context.Context, id string) (*httpSession, int) {
+	if id == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sess, ok := h.sessions[id]
+	if !ok || sess.owner != callerIdentity(ctx) {
+		return nil, http.StatusNotFound
+	}
+	sess.touch()
+	return sess, http.StatusOK
+}
+
+// closeSession terminates a session and ends its GET stream
+func (h *StreamableHTTPHandler) closeSession(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if sess, ok := h.sessions[id]; ok {
+		sess.close()
+		h.server.endSession(sess.session)
+		delete(h.sessions, id)
+	}
+}
+
+func (h *StreamableHTTPHandler) originAllowed(origin string) bool {
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// newSessionID returns a cryptographically random, visible-ASCII session ID
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// acceptsAny reports whether the Accept header allows any of the media types.
+// A missing header is treated as accepting everything.
+func acceptsAny(accept string, mediaTypes ...string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mt := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if mt == "*/*" {
+			return true
+		}
+		for _, want := range mediaTypes {
+			if strings.EqualFold(mt, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeSessionError reports a missing or unknown session ID
+func writeSessionError(w http.ResponseWriter, status int) {
+	message := "Missing Mcp-Session-Id header"
+	if status == http.StatusNotFound {
+		message = "Session not found"
+	}
+	writeHTTPError(w, status, ServerError, message, nil)
+}
+
+// writeHTTPError writes a JSON-RPC error body with the given HTTP status
+func writeHTTPError(w http.ResponseWriter, httpStatus, code int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(errorResponse(nil, code, message, data))
+}
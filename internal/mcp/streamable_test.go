@@ -0,0 +1,344 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	acceptBoth = "application/json, text/event-stream"
+	acceptJSON = "application/json"
+)
+
+func newStreamableTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := NewServer("test", "1.0.0", "2025-11-25")
+	s.RegisterTool(Tool{Name: "echo", InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnly("Echo")},
+		func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+			return "hello", nil
+		})
+
+	handler := NewStreamableHTTPHandler(s, []string{"https://allowed.example.com"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if caller := r.Header.Get(testCallerHeader); caller != "" {
+			r = r.WithContext(WithAuthorizer(r.Context(), testAuthorizer(caller)))
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testCallerHeader names the caller of a request to the test server
+const testCallerHeader = "X-Test-Caller"
+
+// testAuthorizer is a caller that may use everything
+type testAuthorizer string
+
+func (a testAuthorizer) AllowTool(tool Tool) bool         { return true }
+func (a testAuthorizer) AllowResource(uri string) bool    { return true }
+func (a testAuthorizer) AllowService(service string) bool { return true }
+func (a testAuthorizer) Identity() string                 { return "test " + string(a) }
+
+// mcpRequest sends a request to the Streamable HTTP endpoint. Headers are
+// given as name, value pairs.
+func mcpRequest(t *testing.T, method, url, body string, headers ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", acceptBoth)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// decodeResponse reads a JSON-RPC response from a JSON or SSE body
+func decodeResponse(t *testing.T, resp *http.Response) JSONRPCResponse {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		for _, line := range strings.Split(string(body), "\n") {
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				body = []byte(data)
+				break
+			}
+		}
+	}
+	var out JSONRPCResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("decode %q: %v", body, err)
+	}
+	return out
+}
+
+// initializeSession opens a session and returns its ID
+func initializeSession(t *testing.T, url string) string {
+	t.Helper()
+	return initializeSessionVersion(t, url, LatestProtocolVersion)
+}
+
+// initializeSessionVersion opens a session, requesting a protocol version,
+// and returns its ID. Headers are passed on to the initialize request.
+func initializeSessionVersion(t *testing.T, url, version string, headers ...string) string {
+	t.Helper()
+	resp := mcpRequest(t, http.MethodPost, url,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+version+`","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		headers...)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize: status %d", resp.StatusCode)
+	}
+	id := resp.Header.Get(SessionIDHeader)
+	if id == "" {
+		t.Fatal("initialize returned no session ID")
+	}
+	if out := decodeResponse(t, resp); out.Error != nil {
+		t.Fatalf("initialize: %+v", out.Error)
+	}
+	return id
+}
+
+func TestStreamableSessionLifecycle(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	id := initializeSession(t, srv.URL)
+	if other := initializeSession(t, srv.URL); other == id {
+		t.Fatalf("two sessions share ID %s", id)
+	}
+
+	list := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	notification := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		session    string
+		wantStatus int
+	}{
+		{"request", http.MethodPost, list, id, http.StatusOK},
+		{"notification", http.MethodPost, notification, id, http.StatusAccepted},
+		{"missing session", http.MethodPost, list, "", http.StatusBadRequest},
+		{"unknown session", http.MethodPost, list, "0123456789abcdef", http.StatusNotFound},
+		{"delete without session", http.MethodDelete, "", "", http.StatusBadRequest},
+		{"delete", http.MethodDelete, "", id, http.StatusNoContent},
+		{"request after delete", http.MethodPost, list, id, http.StatusNotFound},
+		{"delete after delete", http.MethodDelete, "", id, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		var headers []string
+		if tt.session != "" {
+			headers = append(headers, SessionIDHeader, tt.session)
+		}
+		resp := mcpRequest(t, tt.method, srv.URL, tt.body, headers...)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if tt.wantStatus == http.StatusAccepted {
+			if body, _ := io.ReadAll(resp.Body); len(body) != 0 {
+				t.Errorf("%s: body %q, want none", tt.name, body)
+			}
+		}
+	}
+}
+
+func TestStreamableSessionOwner(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	id := initializeSessionVersion(t, srv.URL, LatestProtocolVersion, testCallerHeader, "alice")
+	list := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		caller     string
+		wantStatus int
+	}{
+		{"owner", http.MethodPost, list, "alice", http.StatusOK},
+		{"other caller", http.MethodPost, list, "bob", http.StatusNotFound},
+		{"no caller", http.MethodPost, list, "", http.StatusNotFound},
+		{"stream of other caller", http.MethodGet, "", "bob", http.StatusNotFound},
+		{"delete by other caller", http.MethodDelete, "", "bob", http.StatusNotFound},
+		{"owner after foreign delete", http.MethodPost, list, "alice", http.StatusOK},
+		{"delete by owner", http.MethodDelete, "", "alice", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		headers := []string{SessionIDHeader, id}
+		if tt.caller != "" {
+			headers = append(headers, testCallerHeader, tt.caller)
+		}
+		resp := mcpRequest(t, tt.method, srv.URL, tt.body, headers...)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
+
+func TestStreamableSessionLimit(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	for i := 0; i < maxSessionsPerCaller; i++ {
+		initializeSessionVersion(t, srv.URL, LatestProtocolVersion, testCallerHeader, "alice")
+	}
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+	resp := mcpRequest(t, http.MethodPost, srv.URL, initialize, testCallerHeader, "alice")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("session over the limit: status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	// Other callers have sessions of their own
+	initializeSessionVersion(t, srv.URL, LatestProtocolVersion, testCallerHeader, "bob")
+}
+
+func TestStreamableBatch(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	id := initializeSession(t, srv.URL)
+
+	resp := mcpRequest(t, http.MethodPost, srv.URL,
+		`[{"jsonrpc":"2.0","id":"a","method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":"b","method":"ping"}]`,
+		SessionIDHeader, id)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("batch: status %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("batch: Content-Type %q, want application/json", got)
+	}
+	var out []JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode batch: %v", err)
+	}
+	if len(out) != 2 || out[0].ID != "a" || out[1].ID != "b" {
+		t.Errorf("batch responses %+v, want a and b", out)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		session    string
+		wantStatus int
+	}{
+		{"notifications only", `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`, id, http.StatusAccepted},
+		{"missing session", `[{"jsonrpc":"2.0","id":1,"method":"ping"}]`, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		var headers []string
+		if tt.session != "" {
+			headers = append(headers, SessionIDHeader, tt.session)
+		}
+		resp := mcpRequest(t, http.MethodPost, srv.URL, tt.body, headers...)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+		if body, _ := io.ReadAll(resp.Body); tt.wantStatus == http.StatusAccepted && len(body) != 0 {
+			t.Errorf("%s: body %q, want none", tt.name, body)
+		}
+	}
+}
+
+func TestStreamableProtocolVersionHeader(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	id := initializeSessionVersion(t, srv.URL, ProtocolVersion20250618)
+	list := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+
+	tests := []struct {
+		name       string
+		version    string
+		wantStatus int
+	}{
+		{"negotiated version", ProtocolVersion20250618, http.StatusOK},
+		{"no header", "", http.StatusOK},
+		{"other supported version", ProtocolVersion20251125, http.StatusBadRequest},
+		{"unknown version", "1999-01-01", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		headers := []string{SessionIDHeader, id}
+		if tt.version != "" {
+			headers = append(headers, ProtocolVersionHeader, tt.version)
+		}
+		resp := mcpRequest(t, http.MethodPost, srv.URL, list, headers...)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
+
+func TestStreamableResponseType(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	id := initializeSession(t, srv.URL)
+
+	call := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{}}}`
+	list := `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`
+
+	tests := []struct {
+		name     string
+		body     string
+		accept   string
+		wantType string
+	}{
+		{"tool call upgrades to SSE", call, acceptBoth, "text/event-stream"},
+		{"tool call without SSE", call, acceptJSON, "application/json"},
+		{"other requests stay JSON", list, acceptBoth, "application/json"},
+	}
+
+	for _, tt := range tests {
+		resp := mcpRequest(t, http.MethodPost, srv.URL, tt.body, SessionIDHeader, id, "Accept", tt.accept)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %d", tt.name, resp.StatusCode)
+			continue
+		}
+		if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+			t.Errorf("%s: Content-Type %q, want %s", tt.name, got, tt.wantType)
+		}
+		if out := decodeResponse(t, resp); out.Error != nil || out.Result == nil {
+			t.Errorf("%s: response %+v, want a result", tt.name, out)
+		}
+	}
+}
+
+func TestStreamableRejects(t *testing.T) {
+	srv := newStreamableTestServer(t)
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		headers    []string
+		wantStatus int
+	}{
+		{"allowed origin", http.MethodPost, initialize, []string{"Origin", "https://allowed.example.com"}, http.StatusOK},
+		{"foreign origin", http.MethodPost, initialize, []string{"Origin", "https://evil.example.com"}, http.StatusForbidden},
+		{"unacceptable", http.MethodPost, initialize, []string{"Accept", "text/html"}, http.StatusNotAcceptable},
+		{"invalid JSON", http.MethodPost, `{"jsonrpc":`, nil, http.StatusBadRequest},
+		{"GET without SSE", http.MethodGet, "", []string{"Accept", acceptJSON}, http.StatusMethodNotAllowed},
+		{"unsupported method", http.MethodPut, initialize, nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		resp := mcpRequest(t, tt.method, srv.URL, tt.body, tt.headers...)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ResourceVersionFunc returns a fingerprint of a resource's current state,
+// such as its modification time. The server reports the resource as updated
+// to subscribers whenever the fingerprint changes.
+type ResourceVersionFunc func(ctx context.Context, uri string, params map[string]string) (string, error)
+
+const (
+	// defaultPollInterval is used when no poll interval is configured
+	defaultPollInterval = 30 * time.Second
+
+	// maxSubscriptions bounds the resources polled, and
+	// maxSessionSubscriptions those one session may subscribe to. Sessions
+	// subscribed to the same resource share its poll.
+	maxSubscriptions        = 1000
+	maxSessionSubscriptions = 100
+)
+
+type resourceWatcher struct {
+	uriTemplate *URITemplate
+	version     ResourceVersionFunc
+}
+
+// subscription is the shared state of all sessions subscribed to one URI
+type subscription struct {
+	watcher  *resourceWatcher
+	params   map[string]string
+	sessions map[*Session]struct{}
+
+	version string
+	known   bool
+}
+
+// RegisterResourceWatcher makes resources matching uriTemplate available to
+// resources/subscribe. While a resource has subscribers, WatchResources
+// polls version and notifies them when it changes. A watcher registered
+// for the same template is replaced. It panics if the URI template is
+// invalid.
+func (s *Server) RegisterResourceWatcher(uriTemplate string, version ResourceVersionFunc) {
+	w := &resourceWatcher{
+		uriTemplate: MustParseURITemplate(uriTemplate),
+		version:     version,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watchers := make([]*resourceWatcher, 0, len(s.watchers)+1)
+	for _, existing := range s.watchers {
+		if existing.uriTemplate.String() != uriTemplate {
+			watchers = append(watchers, existing)
+		}
+	}
+	s.watchers = append(watchers, w)
+}
+
+// SetPollInterval sets how often WatchResources polls subscribed resources.
+// Values below one second restore the default.
+func (s *Server) SetPollInterval(d time.Duration) {
+	if d < time.Second {
+		d = defaultPollInterval
+	}
+	s.pollInterval = d
+}
+
+// WatchResources polls subscribed resources until ctx is done, sending
+// notifications/resources/updated to the subscribers of each resource whose
+// version changed
+func (s *Server) WatchResources(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollResources(ctx)
+		}
+	}
+}
+
+func (s *Server) pollResources(ctx context.Context) {
+	s.subsMu.Lock()
+	subs := make(map[string]*subscription, len(s.subscriptions))
+	for uri, sub := range s.subscriptions {
+		subs[uri] = sub
+	}
+	s.subsMu.Unlock()
+
+	for uri, sub := range subs {
+		pollCtx, cancel := context.WithTimeout(ctx, s.pollInterval)
+		version, err := sub.watcher.version(pollCtx, uri, sub.params)
+		cancel()
+		if err != nil {
+			s.logger.Warn("Resource poll failed", "uri", uri, "error", err)
+			continue
+		}
+
+		s.subsMu.Lock()
+		changed := sub.known && version != sub.version
+		sub.version, sub.known = version, true
+		sessions := make([]*Session, 0, len(sub.sessions))
+		for sess := range sub.sessions {
+			sessions = append(sessions, sess)
+		}
+		s.subsMu.Unlock()
+
+		if !changed {
+			continue
+		}
+
+		s.logger.Info("Resource updated", "uri", uri, "subscribers", len(sessions))
+		notification := newNotification("notifications/resources/updated", ResourceUpdatedNotification{URI: uri})
+		for _, sess := range sessions {
+			if err := sess.transport.Send(ctx, notification); err != nil {
+				s.logger.Warn("Failed to notify subscriber", "uri", uri, "error", err)
+			}
+		}
+	}
+}
+
+func (s *Server) handleSubscribe(ctx context.Context, sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
+	var params SubscribeRequest
+	if err := decodeParams(req, &params); err != nil || params.URI == "" {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", "uri is required")
+	}
+
+	// Notifications are sent after the request completes, which needs a
+	// session that outlives it
+	if _, ok := sess.transport.(persistentTransport); !ok {
+		return errorResponse(req.ID, InvalidRequest, "Subscriptions not supported",
+			"subscriptions require a stdio or /mcp session")
+	}
+
+	if !allowResource(ctx, params.URI) {
+		return errorResponse(req.ID, Forbidden, "Forbidden", "credentials do not allow "+params.URI)
+	}
+
+	watcher, watchParams := s.findWatcher(params.URI)
+	if watcher == nil {
+		return errorResponse(req.ID, InvalidParams, "Resource does not support subscriptions", params.URI)
+	}
+
+	s.subsMu.Lock()
+	sub, exists := s.subscriptions[params.URI]
+	if err := s.checkSubscriptionLimits(sess, sub, exists); err != nil {
+		s.subsMu.Unlock()
+		return errorResponse(req.ID, InvalidRequest, "Too many subscriptions", err.Error())
+	}
+	if !exists {
+		sub = &subscription{
+			watcher:  watcher,
+			params:   watchParams,
+			sessions: make(map[*Session]struct{}),
+		}
+		s.subscriptions[params.URI] = sub
+	}
+	sub.sessions[sess] = struct{}{}
+	s.subsMu.Unlock()
+
+	// Record the current version so the first change is not missed
+	if !exists {
+		if version, err := watcher.version(ctx, params.URI, watchParams); err == nil {
+			s.subsMu.Lock()
+			if !sub.known {
+				sub.version, sub.known = version, true
+			}
+			s.subsMu.Unlock()
+		}
+	}
+
+	return resultResponse(req.ID, map[string]interface{}{})
+}
+
+// checkSubscriptionLimits returns an error if subscribing sess to sub,
+// which is nil unless exists, would exceed a limit. The caller must hold
+// subsMu.
+func (s *Server) checkSubscriptionLimits(sess *Session, sub *subscription, exists bool) error {
+	if exists {
+		if _, ok := sub.sessions[sess]; ok {
+			return nil
+		}
+	} else if len(s.subscriptions) >= maxSubscriptions {
+		return fmt.Errorf("the server polls %d resources already", maxSubscriptions)
+	}
+
+	n := 0
+	for _, other := range s.subscriptions {
+		if _, ok := other.sessions[sess]; ok {
+			n++
+		}
+	}
+	if n >= maxSessionSubscriptions {
+		return fmt.Errorf("a session may subscribe to at most %d resources", maxSessionSubscriptions)
+	}
+	return nil
+}
+
+func (s *Server) handleUnsubscribe(sess *Session, req *JSONRPCRequest) *JSONRPCResponse {
+	var params SubscribeRequest
+	if err := decodeParams(req, &params); err != nil || params.URI == "" {
+		return errorResponse(req.ID, InvalidParams, "Invalid params", "uri is required")
+	}
+
+	s.subsMu.Lock()
+	if sub, ok := s.subscriptions[params.URI]; ok {
+		delete(sub.sessions, sess)
+		if len(sub.sessions) == 0 {
+			delete(s.subscriptions, params.URI)
+		}
+	}
+	s.subsMu.Unlock()
+
+	return resultResponse(req.ID, map[string]interface{}{})
+}
+
+// dropSubscriptions removes all subscriptions of a session that has ended
+func (s *Server) dropSubscriptions(sess *Session) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	for uri, sub := range s.subscriptions {
+		delete(sub.sessions, sess)
+		if len(sub.sessions) == 0 {
+			delete(s.subscriptions, uri)
+		}
+	}
+}
+
+// dropWatcherSubscriptions removes the subscriptions served by watchers
+// that were unregistered; their resources no longer exist
+func (s *Server) dropWatcherSubscriptions(watchers map[*resourceWatcher]bool) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	for uri, sub := range s.subscriptions {
+		if watchers[sub.watcher] {
+			delete(s.subscriptions, uri)
+		}
+	}
+}
+
+// findWatcher returns the first watcher whose template matches uri
+func (s *Server) findWatcher(uri string) (*resourceWatcher, map[string]string) {
+	s.mu.RLock()
+	watchers := s.watchers
+	s.mu.RUnlock()
+
+	for _, w := range watchers {
+		if params, ok := w.uriTemplate.Match(uri); ok {
+			return w, params
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrNoBackChannel is returned by transports that can only answer requests
+// and have no way to deliver server-initiated messages.
+var ErrNoBackChannel = errors.New("transport has no server-to-client channel")
+
+// Transport connects a client to the server. Incoming messages are passed to
+// Server.Dispatch together with the Session they belong to; Send delivers
+// server-initiated messages (notifications, requests) back to that client.
+type Transport interface {
+	// Name labels the transport in metrics and logs (e.g. "stdio", "http")
+	Name() string
+
+	// Send delivers a message to the client
+	Send(ctx context.Context, msg interface{}) error
+}
+
+// persistentTransport is implemented by transports whose sessions outlive a
+// single request, so the server can notify them later, e.g. of resource
+// updates
+type persistentTransport interface {
+	Transport
+	persistent()
+}
+
+// intermittentTransport is implemented by persistent transports that can
+// only reach the client while it has a stream open, such as Streamable
+// HTTP
+type intermittentTransport interface {
+	// connected reports whether a message sent in ctx would be read by
+	// the client now
+	connected(ctx context.Context) bool
+}
+
+// canRequest reports whether a server-initiated request made in ctx can
+// reach the client, so that waiting for its answer makes sense
+func (sess *Session) canRequest(ctx context.Context) bool {
+	if _, ok := sess.transport.(persistentTransport); !ok {
+		return false
+	}
+	if t, ok := sess.transport.(intermittentTransport); ok {
+		return t.connected(ctx)
+	}
+	return true
+}
+
+// stdioTransport writes newline-delimited JSON-RPC messages
+type stdioTransport struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (t *stdioTransport) Name() string {
+	return "stdio"
+}
+
+func (t *stdioTransport) persistent() {}
+
+func (t *stdioTransport) Send(ctx context.Context, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	data = append(data, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.out.Write(data); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	return nil
+}
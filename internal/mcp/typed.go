@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedToolHandler is a tool handler that receives its arguments decoded
+// into a struct
+type TypedToolHandler[T any] func(ctx context.Context, args T) (interface{}, error)
+
+// RegisterTypedTool registers a tool whose arguments are decoded into T, a
+// struct. The input schema is derived from T's exported fields:
+//
+//   - the json tag names the argument
+//   - the description tag documents it
+//   - the schema tag lists comma-separated keywords: required, default=v,
+//     minimum=n, maximum=n, enum=a|b|c, format=f and pattern=re (last, since
+//     the pattern may itself contain commas)
+//
+// Properties already set on tool.InputSchema take precedence over derived
+// ones, for nested schemas a Go type cannot express. Before the handler runs,
+// arguments are coerced, defaulted and validated like those of any tool, so
+// fields hold the schema default when the client omits them.
+//
+//	type getLogsArgs struct {
+//		ContainerID string `json:"container_id" description:"Container ID or name" schema:"required"`
+//		Tail        int    `json:"tail" description:"Number of log lines" schema:"default=100,minimum=1"`
+//	}
+//
+//	mcp.RegisterTypedTool(server, mcp.Tool{Name: "get_logs"}, func(ctx context.Context, args getLogsArgs) (interface{}, error) {
+//		...
+//	})
+func RegisterTypedTool[T any](s *Server, tool Tool, handler TypedToolHandler[T]) {
+	properties, required := structSchema(reflect.TypeOf((*T)(nil)).Elem())
+
+	for name, prop := range tool.InputSchema.Properties {
+		properties[name] = prop
+	}
+	for _, name := range tool.InputSchema.Required {
+		if !contains(required, name) {
+			required = append(required, name)
+		}
+	}
+
+	tool.InputSchema = InputSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+	if len(properties) == 0 {
+		tool.InputSchema.Properties = nil
+	}
+
+	s.RegisterTool(tool, func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+		var args T
+		if err := decodeArguments(arguments, &args); err != nil {
+			return nil, err
+		}
+		return handler(ctx, args)
+	})
+}
+
+// decodeArguments decodes tool arguments into the struct pointed to by v
+func decodeArguments(arguments map[string]interface{}, v interface{}) error {
+	if arguments == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structSchema derives the properties and required names of a struct type.
+// It panics when t is not a struct or a tag is malformed, as both are
+// programming errors caught at registration.
+func structSchema(t reflect.Type) (map[string]Property, []string) {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("mcp: tool arguments must be a struct, got %s", t))
+	}
+
+	properties := make(map[string]Property)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs contribute their fields, as in encoding/json
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded, embeddedRequired := structSchema(field.Type)
+			for n, p := range embedded {
+				properties[n] = p
+			}
+			required = append(required, embeddedRequired...)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := typeSchema(field.Type)
+		prop.Description = field.Tag.Get("description")
+		if applySchemaTag(&prop, field) {
+			required = append(required, name)
+		}
+		properties[name] = prop
+	}
+
+	return properties, required
+}
+
+// typeSchema maps a Go type to the JSON Schema describing its JSON encoding
+func typeSchema(t reflect.Type) Property {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return Property{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Property{Type: "string"}
+	case reflect.Bool:
+		return Property{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Property{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Property{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		items := typeSchema(t.Elem())
+		return Property{Type: "array", Items: &items}
+	case reflect.Map:
+		return Property{Type: "object"}
+	case reflect.Struct:
+		properties, required := structSchema(t)
+		return Property{Type: "object", Properties: properties, Required: required}
+	default:
+		// interface{} and anything else accept any JSON value
+		return Property{}
+	}
+}
+
+// applySchemaTag applies the schema tag of field to prop and reports whether
+// the field is required
+func applySchemaTag(prop *Property, field reflect.StructField) bool {
+	tag := field.Tag.Get("schema")
+	required := false
+
+	for tag != "" {
+		var item string
+		if strings.HasPrefix(tag, "pattern=") {
+			item, tag = tag, ""
+		} else {
+			item, tag, _ = strings.Cut(tag, ",")
+		}
+
+		key, value, _ := strings.Cut(item, "=")
+		switch key {
+		case "required":
+			required = true
+		case "default":
+			prop.Default = parseDefault(field, prop.Type, value)
+		case "minimum":
+			prop.Minimum = Float(parseTagFloat(field, key, value))
+		case "maximum":
+			prop.Maximum = Float(parseTagFloat(field, key, value))
+		case "enum":
+			prop.Enum = strings.Split(value, "|")
+		case "format":
+			prop.Format = value
+		case "pattern":
+			prop.Pattern = value
+		default:
+			panic(fmt.Sprintf("mcp: field %s: unknown schema keyword %q", field.Name, key))
+		}
+	}
+
+	return required
+}
+
+func parseDefault(field reflect.StructField, schemaType, value string) interface{} {
+	switch schemaType {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("mcp: field %s: invalid default %q", field.Name, value))
+		}
+		return n
+	case "number":
+		return parseTagFloat(field, "default", value)
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			panic(fmt.Sprintf("mcp: field %s: invalid default %q", field.Name, value))
+		}
+		return b
+	default:
+		return value
+	}
+}
+
+func parseTagFloat(field reflect.StructField, key, value string) float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("mcp: field %s: invalid %s %q", field.Name, key, value))
+	}
+	return f
+}
@@ -1,21 +1,58 @@
 package mcp
 
+import "encoding/json"
+
+// JSON-RPC 2.0 error codes
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+
+	// ServerError is the generic implementation-defined server error
+	ServerError = -32000
+
+	// Unauthorized is returned for missing, invalid or expired credentials
+	Unauthorized = -32001
+
+	// Forbidden is returned when the caller's credentials do not allow a
+	// tool or resource
+	Forbidden = -32003
+
+	// RateLimited is returned when a rate limit or concurrency cap is
+	// exceeded; the error data says when to retry
+	RateLimited = -32029
+)
+
 // JSON-RPC 2.0 message types
 
+// JSONRPCRequest is a request or notification. Messages received from a
+// client are decoded into it as well; a response to a server-initiated
+// request has no Method and sets Result or Error instead.
 type JSONRPCRequest struct {
 	JSONRPC string      `json:"jsonrpc"`
 	ID      interface{} `json:"id,omitempty"`
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params,omitempty"`
+
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *JSONRPCError   `json:"error,omitempty"`
 }
 
 type JSONRPCResponse struct {
-	JSONRPC string      `json:"jsonrpc"`
-	ID      interface{} `json:"id,omitempty"`
-	Result  interface{} `json:"result,omitempty"`
+	JSONRPC string        `json:"jsonrpc"`
+	ID      interface{}   `json:"id"`
+	Result  interface{}   `json:"result,omitempty"`
 	Error   *JSONRPCError `json:"error,omitempty"`
 }
 
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 type JSONRPCError struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
@@ -24,16 +61,33 @@ type JSONRPCError struct {
 
 // MCP Protocol types
 
+// RequestMeta is the _meta object clients may attach to request params
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+type ProgressNotification struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         float64     `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+type CancelledNotification struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
 type InitializeRequest struct {
-	ProtocolVersion string               `json:"protocolVersion"`
-	Capabilities    ClientCapabilities   `json:"capabilities"`
-	ClientInfo      Implementation       `json:"clientInfo"`
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      Implementation     `json:"clientInfo"`
 }
 
 type InitializeResult struct {
-	ProtocolVersion string               `json:"protocolVersion"`
-	Capabilities    ServerCapabilities   `json:"capabilities"`
-	ServerInfo      Implementation       `json:"serverInfo"`
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
 }
 
 type Implementation struct {
@@ -42,8 +96,9 @@ type Implementation struct {
 }
 
 type ClientCapabilities struct {
-	Roots      *RootsCapability      `json:"roots,omitempty"`
-	Sampling   *SamplingCapability   `json:"sampling,omitempty"`
+	Roots       *RootsCapability       `json:"roots,omitempty"`
+	Sampling    *SamplingCapability    `json:"sampling,omitempty"`
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
 }
 
 type ServerCapabilities struct {
@@ -51,6 +106,8 @@ type ServerCapabilities struct {
 	Resources *ResourcesCapability `json:"resources,omitempty"`
 	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
 	Logging   *LoggingCapability   `json:"logging,omitempty"`
+
+	Completions *CompletionsCapability `json:"completions,omitempty"`
 }
 
 type RootsCapability struct {
@@ -59,6 +116,8 @@ type RootsCapability struct {
 
 type SamplingCapability struct{}
 
+type ElicitationCapability struct{}
+
 type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
@@ -72,6 +131,8 @@ type PromptsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+type CompletionsCapability struct{}
+
 type LoggingCapability struct{}
 
 // Tool types
@@ -80,22 +141,72 @@ type Tool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
 	InputSchema InputSchema `json:"inputSchema"`
+
+	// OutputSchema, when set, describes the structuredContent of results.
+	// It must have type "object".
+	OutputSchema *InputSchema `json:"outputSchema,omitempty"`
+
+	// Annotations describe the tool's behaviour to clients. A tool without
+	// annotations is assumed to be destructive, as the MCP defaults imply.
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints about a tool's behaviour. Unset hints take the
+// MCP defaults: not read-only, destructive, not idempotent, open world.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
 type InputSchema struct {
-	Type       string                 `json:"type"`
-	Properties map[string]Property    `json:"properties,omitempty"`
-	Required   []string               `json:"required,omitempty"`
+	Type       string              `json:"type"`
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
 }
 
+// Property is a JSON Schema describing one value. Objects use Properties
+// and Required, arrays use Items, and OneOf lists alternative schemas.
 type Property struct {
-	Type        string   `json:"type"`
-	Description string   `json:"description"`
-	Enum        []string `json:"enum,omitempty"`
+	Type        string      `json:"type,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+
+	// EnumNames labels the Enum values for display, e.g. in elicitation
+	// forms
+	EnumNames []string `json:"enumNames,omitempty"`
+
+	// Numbers
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Strings
+	Pattern string `json:"pattern,omitempty"`
+	Format  string `json:"format,omitempty"`
+
+	// Arrays
+	Items *Property `json:"items,omitempty"`
+
+	// Objects
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
+
+	OneOf []Property `json:"oneOf,omitempty"`
+}
+
+// PaginatedRequest is the params of the list methods. Cursor is the
+// nextCursor of the previous page; empty for the first page.
+type PaginatedRequest struct {
+	Cursor string `json:"cursor,omitempty"`
 }
 
 type ListToolsResult struct {
-	Tools []Tool `json:"tools"`
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 type CallToolRequest struct {
@@ -104,13 +215,23 @@ type CallToolRequest struct {
 }
 
 type CallToolResult struct {
-	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
 }
 
+// Content is a block of tool output. Which fields are set depends on Type:
+// text uses Text; image and audio use Data (base64) and MimeType; resource
+// uses Resource; resource_link uses URI, Name, Description and MimeType.
 type Content struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type        string            `json:"type"`
+	Text        string            `json:"text,omitempty"`
+	Data        string            `json:"data,omitempty"`
+	MimeType    string            `json:"mimeType,omitempty"`
+	Resource    *ResourceContents `json:"resource,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
 }
 
 // Resource types
@@ -123,13 +244,36 @@ type Resource struct {
 }
 
 type ListResourcesResult struct {
-	Resources []Resource `json:"resources"`
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ResourceTemplate describes a family of resources by an RFC 6570 URI
+// template, e.g. grafana://dashboard/{uid}
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string             `json:"nextCursor,omitempty"`
 }
 
 type ReadResourceRequest struct {
 	URI string `json:"uri"`
 }
 
+type SubscribeRequest struct {
+	URI string `json:"uri"`
+}
+
+type ResourceUpdatedNotification struct {
+	URI string `json:"uri"`
+}
+
 type ReadResourceResult struct {
 	Contents []ResourceContents `json:"contents"`
 }
@@ -144,9 +288,14 @@ type ResourceContents struct {
 // Prompt types
 
 type Prompt struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description,omitempty"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
 	Arguments   []PromptArgument `json:"arguments,omitempty"`
+
+	// Services are the backends whose resources the prompt embeds. Only
+	// callers that may read from all of them can list, get or complete
+	// the prompt.
+	Services []string `json:"-"`
 }
 
 type PromptArgument struct {
@@ -156,12 +305,13 @@ type PromptArgument struct {
 }
 
 type ListPromptsResult struct {
-	Prompts []Prompt `json:"prompts"`
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
 }
 
 type GetPromptRequest struct {
-	Name      string                 `json:"name"`
-	Arguments map[string]string      `json:"arguments,omitempty"`
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
 }
 
 type GetPromptResult struct {
@@ -169,12 +319,127 @@ type GetPromptResult struct {
 	Messages    []PromptMessage `json:"messages"`
 }
 
+// PromptMessage is one message of a rendered prompt. Content may be text or
+// an embedded resource.
 type PromptMessage struct {
-	Role    string        `json:"role"`
-	Content PromptContent `json:"content"`
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
 }
 
-type PromptContent struct {
+// Completion types
+
+// Reference types accepted by completion/complete. RefTool is an extension
+// to the MCP specification, which only completes prompts and resources.
+const (
+	RefPrompt   = "ref/prompt"
+	RefResource = "ref/resource"
+	RefTool     = "ref/tool"
+)
+
+// CompleteReference identifies what is being completed: a prompt or tool
+// by Name, or a resource template by URI
+type CompleteReference struct {
 	Type string `json:"type"`
-	Text string `json:"text"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+type CompleteArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CompleteContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type CompleteRequest struct {
+	Ref      CompleteReference `json:"ref"`
+	Argument CompleteArgument  `json:"argument"`
+	Context  *CompleteContext  `json:"context,omitempty"`
+}
+
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// Logging types
+
+type SetLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LoggingMessageNotification is the params of notifications/message
+type LoggingMessageNotification struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+// Sampling types
+
+// SamplingMessage is a message of a sampling conversation. Content is
+// text, image or audio.
+type SamplingMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ModelPreferences guide the client's choice of model. Priorities range
+// from 0 to 1; hints name models or model families in order of preference.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         float64     `json:"costPriority,omitempty"`
+	SpeedPriority        float64     `json:"speedPriority,omitempty"`
+	IntelligencePriority float64     `json:"intelligencePriority,omitempty"`
+}
+
+type ModelHint struct {
+	Name string `json:"name"`
+}
+
+// CreateMessageRequest is the params of sampling/createMessage
+type CreateMessageRequest struct {
+	Messages         []SamplingMessage `json:"messages"`
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+}
+
+type CreateMessageResult struct {
+	Role       string  `json:"role"`
+	Content    Content `json:"content"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+}
+
+// Elicitation types
+
+// Elicitation actions
+const (
+	ElicitAccept  = "accept"
+	ElicitDecline = "decline"
+	ElicitCancel  = "cancel"
+)
+
+// ElicitRequest is the params of elicitation/create. RequestedSchema is a
+// flat object whose properties are strings, numbers, booleans or enums.
+type ElicitRequest struct {
+	Message         string      `json:"message"`
+	RequestedSchema InputSchema `json:"requestedSchema"`
+}
+
+// ElicitResult is the user's answer. Content holds the submitted values
+// when Action is accept.
+type ElicitResult struct {
+	Action  string                 `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
 }
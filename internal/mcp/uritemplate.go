@@ -0,0 +1,313 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URITemplate is a parsed RFC 6570 URI template. It expands variables into
+// URIs and, in reverse, matches URIs and extracts the variables, which is
+// how resource templates are dispatched.
+//
+// Matching requires every variable outside a query expression ({?x} or
+// {&x}) to be present. Query variables are optional and may appear in any
+// order. Prefix modifiers ({var:3}) are ignored when matching, and explode
+// ({/var*}) is only meaningful for path segments.
+type URITemplate struct {
+	raw   string
+	parts []templatePart
+
+	re        *regexp.Regexp
+	groups    []templateVar // variables in capture group order
+	queryVars []string      // variables of a trailing query expression
+}
+
+type templatePart struct {
+	literal string
+	op      byte // 0 for literals
+	vars    []templateVar
+}
+
+type templateVar struct {
+	name    string
+	explode bool
+}
+
+// templateOp holds the expansion rules of an operator (RFC 6570 appendix A)
+type templateOp struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+var templateOps = map[byte]templateOp{
+	'+': {first: "", sep: ",", allowReserved: true},
+	'#': {first: "#", sep: ",", allowReserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+// simpleOp is the rule set of expressions without an operator
+var simpleOp = templateOp{first: "", sep: ","}
+
+var varNamePattern = regexp.MustCompile(`^([A-Za-z0-9_]|%[0-9A-Fa-f]{2})([A-Za-z0-9_.]|%[0-9A-Fa-f]{2})*$`)
+
+// ParseURITemplate parses an RFC 6570 URI template
+func ParseURITemplate(template string) (*URITemplate, error) {
+	t := &URITemplate{raw: template}
+
+	rest := template
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:open]})
+		}
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("uri template %q: unclosed expression", template)
+		}
+		part, err := parseExpression(rest[open+1 : open+end])
+		if err != nil {
+			return nil, fmt.Errorf("uri template %q: %w", template, err)
+		}
+		t.parts = append(t.parts, part)
+		rest = rest[open+end+1:]
+	}
+
+	if err := t.compile(); err != nil {
+		return nil, fmt.Errorf("uri template %q: %w", template, err)
+	}
+	return t, nil
+}
+
+// MustParseURITemplate is like ParseURITemplate but panics on error
+func MustParseURITemplate(template string) *URITemplate {
+	t, err := ParseURITemplate(template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func parseExpression(expr string) (templatePart, error) {
+	part := templatePart{}
+	if expr != "" {
+		if _, ok := templateOps[expr[0]]; ok {
+			part.op = expr[0]
+			expr = expr[1:]
+		} else if strings.ContainsRune("=,!@|", rune(expr[0])) {
+			return part, fmt.Errorf("reserved operator %q", expr[0])
+		}
+	}
+	if part.op == 0 {
+		part.op = ' '
+	}
+
+	for _, spec := range strings.Split(expr, ",") {
+		v := templateVar{name: spec}
+		if strings.HasSuffix(spec, "*") {
+			v.name, v.explode = strings.TrimSuffix(spec, "*"), true
+		} else if name, _, ok := strings.Cut(spec, ":"); ok {
+			v.name = name
+		}
+		if !varNamePattern.MatchString(v.name) {
+			return part, fmt.Errorf("invalid variable name %q", spec)
+		}
+		part.vars = append(part.vars, v)
+	}
+	return part, nil
+}
+
+func (p templatePart) rules() templateOp {
+	if op, ok := templateOps[p.op]; ok {
+		return op
+	}
+	return simpleOp
+}
+
+// compile builds the regular expression used by Match
+func (t *URITemplate) compile() error {
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i, part := range t.parts {
+		if part.op == 0 {
+			b.WriteString(regexp.QuoteMeta(part.literal))
+			continue
+		}
+
+		if part.op == '?' || part.op == '&' {
+			if i != len(t.parts)-1 {
+				return fmt.Errorf("query expressions must come last")
+			}
+			for _, v := range part.vars {
+				t.queryVars = append(t.queryVars, v.name)
+			}
+			b.WriteString(`(?:[?&]([^#]*))?`)
+			continue
+		}
+
+		rules := part.rules()
+		for j, v := range part.vars {
+			if j == 0 {
+				b.WriteString(regexp.QuoteMeta(rules.first))
+			} else {
+				b.WriteString(regexp.QuoteMeta(rules.sep))
+			}
+			if rules.named {
+				b.WriteString(regexp.QuoteMeta(v.name) + "=")
+			}
+			b.WriteString(valuePattern(part.op, v, len(part.vars) > 1))
+			t.groups = append(t.groups, v)
+		}
+	}
+
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return err
+	}
+	t.re = re
+	return nil
+}
+
+// valuePattern returns the capture group matching one variable's value
+func valuePattern(op byte, v templateVar, multi bool) string {
+	switch op {
+	case '+', '#':
+		if multi {
+			return `([^,?#]+)`
+		}
+		return `([^?#]+)`
+	case '/':
+		if v.explode {
+			return `([^?#]+)`
+		}
+		return `([^/?#]+)`
+	case '.':
+		return `([^/?#.]+)`
+	case ';':
+		return `([^;/?#]*)`
+	default:
+		return `([^/?#,]+)`
+	}
+}
+
+// String returns the template as written
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// hasVariable reports whether the template has a variable named name
+func (t *URITemplate) hasVariable(name string) bool {
+	for _, part := range t.parts {
+		for _, v := range part.vars {
+			if v.name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Match reports whether uri matches the template and returns the decoded
+// variable values
+func (t *URITemplate) Match(uri string) (map[string]string, bool) {
+	m := t.re.FindStringSubmatch(uri)
+	if m == nil {
+		return nil, false
+	}
+
+	params := make(map[string]string, len(t.groups)+len(t.queryVars))
+	for i, v := range t.groups {
+		value, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		params[v.name] = value
+	}
+
+	if len(t.queryVars) > 0 {
+		query, err := url.ParseQuery(m[len(m)-1])
+		if err != nil {
+			return nil, false
+		}
+		for _, name := range t.queryVars {
+			if values, ok := query[name]; ok && len(values) > 0 {
+				params[name] = values[0]
+			}
+		}
+	}
+
+	return params, true
+}
+
+// Expand builds a URI from the template. Variables missing from params are
+// left out, as RFC 6570 specifies for undefined variables.
+func (t *URITemplate) Expand(params map[string]string) string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.op == 0 {
+			b.WriteString(part.literal)
+			continue
+		}
+
+		rules := part.rules()
+		first := true
+		for _, v := range part.vars {
+			value, ok := params[v.name]
+			if !ok {
+				continue
+			}
+			if first {
+				b.WriteString(rules.first)
+				first = false
+			} else {
+				b.WriteString(rules.sep)
+			}
+			if rules.named {
+				b.WriteString(v.name)
+				if value == "" {
+					b.WriteString(rules.ifEmpty)
+					continue
+				}
+				b.WriteString("=")
+			}
+			b.WriteString(escapeTemplateValue(value, rules.allowReserved || (part.op == '/' && v.explode)))
+		}
+	}
+	return b.String()
+}
+
+// escapeTemplateValue percent-encodes everything but unreserved characters,
+// and reserved characters too unless allowReserved is set
+func escapeTemplateValue(value string, allowReserved bool) string {
+	const reserved = ":/?#[]@!$&'()*+,;="
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case allowReserved && strings.IndexByte(reserved, c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURITemplateExpand(t *testing.T) {
+	// Variables and most cases from RFC 6570 section 3.2
+	params := map[string]string{
+		"var":   "value",
+		"hello": "Hello World!",
+		"path":  "/foo/bar",
+		"x":     "1024",
+		"y":     "768",
+		"empty": "",
+		"segs":  "a/b",
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{path}", "%2Ffoo%2Fbar"},
+		{"{x,y}", "1024,768"},
+		{"{var,undef}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"{#path}", "#/foo/bar"},
+		{"{#undef}", ""},
+		{"X{.var}", "X.value"},
+		{"X{.x,y}", "X.1024.768"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/segs}", "/a%2Fb"},
+		{"{/segs*}", "/a/b"},
+		{"{;x,y}", ";x=1024;y=768"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{?x,y}", "?x=1024&y=768"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?undef}", ""},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{var:3}", "value"},
+		{"plain://literal", "plain://literal"},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseURITemplate(tt.template)
+		if err != nil {
+			t.Errorf("ParseURITemplate(%q): %v", tt.template, err)
+			continue
+		}
+		if got := tmpl.Expand(params); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestURITemplateMatch(t *testing.T) {
+	tests := []struct {
+		template string
+		uri      string
+		want     map[string]string // nil when uri does not match
+	}{
+		{"portainer://endpoint/{endpoint}/container/{id}/logs", "portainer://endpoint/1/container/web/logs",
+			map[string]string{"endpoint": "1", "id": "web"}},
+		{"portainer://endpoint/{endpoint}/container/{id}/logs", "portainer://endpoint/1/container/web", nil},
+		{"portainer://endpoint/{endpoint}/container/{id}/logs", "portainer://endpoint/1/container/a/b/logs", nil},
+		{"portainer://endpoint/{endpoint}/container/{id}/logs", "grafana://endpoint/1/container/web/logs", nil},
+		{"{x,y}", "1024,768", map[string]string{"x": "1024", "y": "768"}},
+		{"{var:3}", "value", map[string]string{"var": "value"}},
+
+		// Reserved expansion spans path segments
+		{"file:///{+path}", "file:///a/b/c.txt", map[string]string{"path": "a/b/c.txt"}},
+		{"file:///{+path}", "file:///a?b", nil},
+		{"page{#section}", "page#intro", map[string]string{"section": "intro"}},
+
+		// Label and path segment expansion
+		{"report{.format}", "report.json", map[string]string{"format": "json"}},
+		{"report{.format}", "report.tar.gz", nil},
+		{"docs{/page}", "docs/intro", map[string]string{"page": "intro"}},
+		{"docs{/page}", "docs/a/b", nil},
+		{"docs{/pages*}", "docs/a/b", map[string]string{"pages": "a/b"}},
+		{"docs{/section,page}", "docs/guide/intro", map[string]string{"section": "guide", "page": "intro"}},
+
+		// Path-style parameters
+		{"map{;x,y}", "map;x=1024;y=768", map[string]string{"x": "1024", "y": "768"}},
+		{"map{;x,y}", "map;y=768;x=1024", nil},
+
+		// Query variables are optional and unordered
+		{"search://q{?term,limit}", "search://q?limit=5&term=go%20lang", map[string]string{"term": "go lang", "limit": "5"}},
+		{"search://q{?term,limit}", "search://q?term=go", map[string]string{"term": "go"}},
+		{"search://q{?term,limit}", "search://q", map[string]string{}},
+		{"search://q{?term,limit}", "search://q?other=1", map[string]string{}},
+		{"search://q?fixed=1{&term}", "search://q?fixed=1&term=go", map[string]string{"term": "go"}},
+
+		// Literals are matched literally, not as regular expressions
+		{"a+b(c)[d]^$|.{id}", "a+b(c)[d]^$|.x", map[string]string{"id": "x"}},
+		{"a+b(c)[d]^$|.{id}", "aab(c)[d]^$|.x", nil},
+		{"v1.0/{id}", "v1x0/5", nil},
+
+		// Values are percent-decoded
+		{"tag://{name}", "tag://Hello%20World%21", map[string]string{"name": "Hello World!"}},
+		{"tag://{name}", "tag://%C3%BC", map[string]string{"name": "ü"}},
+		{"tag://{name}", "tag://%zz", nil},
+		{"tag://{name}", "tag://", nil},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseURITemplate(tt.template)
+		if err != nil {
+			t.Errorf("ParseURITemplate(%q): %v", tt.template, err)
+			continue
+		}
+		got, ok := tmpl.Match(tt.uri)
+		if tt.want == nil {
+			if ok {
+				t.Errorf("%q matched %q with %v, want no match", tt.template, tt.uri, got)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%q did not match %q", tt.template, tt.uri)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q matching %q = %v, want %v", tt.template, tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestURITemplateRoundTrip(t *testing.T) {
+	templates := []string{"res://{value}", "res://{+value}", "res://x{/value}", "res://x{?value}", "res://x{;value}"}
+	values := []string{"plain", "Hello World!", "50%", "ü", "a,b", "a&b=c", "a;b", "a/b c", "~user"}
+
+	for _, template := range templates {
+		tmpl := MustParseURITemplate(template)
+		for _, value := range values {
+			uri := tmpl.Expand(map[string]string{"value": value})
+			got, ok := tmpl.Match(uri)
+			if !ok || got["value"] != value {
+				t.Errorf("%s: %q expanded to %q, which matches %q", template, value, uri, got["value"])
+			}
+		}
+	}
+}
+
+func TestParseURITemplateErrors(t *testing.T) {
+	templates := []string{
+		"res://{id",
+		"res://{}",
+		"res://{bad name}",
+		"res://{=id}",
+		"res://{!id}",
+		"res://{id-1}",
+		"res://{?q}/after",
+	}
+
+	for _, template := range templates {
+		if _, err := ParseURITemplate(template); err == nil {
+			t.Errorf("ParseURITemplate(%q) succeeded, want error", template)
+		}
+	}
+}
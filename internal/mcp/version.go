@@ -0,0 +1,161 @@
+package mcp
+
+import "fmt"
+
+// Protocol versions, by the date of the MCP specification revision.
+// Versions compare in date order as strings.
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+	ProtocolVersion20251125 = "2025-11-25"
+
+	// LatestProtocolVersion is the newest version the server implements
+	LatestProtocolVersion = ProtocolVersion20251125
+)
+
+// SupportedProtocolVersions lists the versions the server can negotiate,
+// newest first
+var SupportedProtocolVersions = []string{
+	ProtocolVersion20251125,
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// Features and the protocol version that introduced them
+const (
+	// Tool annotations, audio content and completion/complete
+	versionAnnotations = ProtocolVersion20250326
+	versionAudio       = ProtocolVersion20250326
+	versionCompletions = ProtocolVersion20250326
+
+	// Tool output schemas, structuredContent and resource links
+	versionStructuredContent = ProtocolVersion20250618
+	versionResourceLinks     = ProtocolVersion20250618
+
+	// elicitation/create
+	versionElicitation = ProtocolVersion20250618
+)
+
+// protocolVersions returns the supported versions up to and including
+// latest, newest first. An unknown latest allows all supported versions.
+func protocolVersions(latest string) []string {
+	for i, v := range SupportedProtocolVersions {
+		if v == latest {
+			return SupportedProtocolVersions[i:]
+		}
+	}
+	return SupportedProtocolVersions
+}
+
+// negotiateVersion returns requested if the server supports it, otherwise
+// the newest version the server offers, which the client may then reject
+func (s *Server) negotiateVersion(requested string) string {
+	if s.supportsVersion(requested) {
+		return requested
+	}
+	return s.protocolVersions[0]
+}
+
+// supportsVersion reports whether version can be negotiated
+func (s *Server) supportsVersion(version string) bool {
+	for _, v := range s.protocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// adaptToolResult removes the parts of a tools/call result that the
+// session's protocol version predates, and converts content blocks it
+// does not know
+func adaptToolResult(sess *Session, resp *JSONRPCResponse) {
+	version := sess.ProtocolVersion()
+	if resp == nil || version >= versionStructuredContent {
+		return
+	}
+	switch result := resp.Result.(type) {
+	case CallToolResult:
+		result.StructuredContent = nil
+		result.Content = adaptContent(version, result.Content)
+		resp.Result = result
+	case *CallToolResult:
+		adapted := *result
+		adapted.StructuredContent = nil
+		adapted.Content = adaptContent(version, result.Content)
+		resp.Result = &adapted
+	}
+}
+
+// adaptPromptResult converts the content blocks of a prompts/get result
+// that the session's protocol version does not know
+func adaptPromptResult(sess *Session, resp *JSONRPCResponse) {
+	version := sess.ProtocolVersion()
+	if resp == nil || version >= versionResourceLinks {
+		return
+	}
+	result, ok := resp.Result.(*GetPromptResult)
+	if !ok {
+		return
+	}
+	adapted := *result
+	adapted.Messages = make([]PromptMessage, len(result.Messages))
+	for i, msg := range result.Messages {
+		msg.Content = adaptContentBlock(version, msg.Content)
+		adapted.Messages[i] = msg
+	}
+	resp.Result = &adapted
+}
+
+// adaptContent returns content with the blocks that version predates
+// converted to text. The handler's slice is left as is.
+func adaptContent(version string, content []Content) []Content {
+	if version >= versionResourceLinks {
+		return content
+	}
+	adapted := make([]Content, len(content))
+	for i, c := range content {
+		adapted[i] = adaptContentBlock(version, c)
+	}
+	return adapted
+}
+
+// adaptContentBlock converts a resource link or audio block that version
+// predates to a text block describing it
+func adaptContentBlock(version string, c Content) Content {
+	switch {
+	case c.Type == ContentTypeResourceLink && version < versionResourceLinks:
+		text := "Resource " + c.URI
+		if c.Name != "" && c.Name != c.URI {
+			text = "Resource " + c.Name + " at " + c.URI
+		}
+		if c.Description != "" {
+			text += ": " + c.Description
+		}
+		return TextContent(text)
+	case c.Type == ContentTypeAudio && version < versionAudio:
+		return TextContent(fmt.Sprintf("[%s audio omitted: protocol version %s does not support audio content]", c.MimeType, version))
+	}
+	return c
+}
+
+// adaptTools removes the tool fields that the session's protocol version
+// predates
+func adaptTools(sess *Session, tools []Tool) []Tool {
+	version := sess.ProtocolVersion()
+	if version >= versionStructuredContent {
+		return tools
+	}
+
+	adapted := make([]Tool, len(tools))
+	for i, tool := range tools {
+		tool.OutputSchema = nil
+		if version < versionAnnotations {
+			tool.Annotations = nil
+		}
+		adapted[i] = tool
+	}
+	return adapted
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// sessionAtVersion returns a session that negotiated version
+func sessionAtVersion(t *testing.T, s *Server, version string) *Session {
+	t.Helper()
+	sess := NewSession(&stdioTransport{out: io.Discard})
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"` + version + `","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+	} {
+		var req JSONRPCRequest
+		if err := json.Unmarshal([]byte(msg), &req); err != nil {
+			t.Fatalf("decode %s: %v", msg, err)
+		}
+		if resp := s.Dispatch(context.Background(), sess, &req); resp != nil && resp.Error != nil {
+			t.Fatalf("%s: %+v", req.Method, resp.Error)
+		}
+	}
+	if got := sess.ProtocolVersion(); got != version {
+		t.Fatalf("negotiated %s, want %s", got, version)
+	}
+	return sess
+}
+
+func TestAdaptContent(t *testing.T) {
+	link := ResourceLink(Resource{URI: "portainer://containers", Name: "Containers", Description: "All containers"})
+	bareLink := ResourceLink(Resource{URI: "prometheus://targets", Name: "prometheus://targets"})
+	audio := AudioContent([]byte("RIFF"), "audio/wav")
+	text := TextContent("hello")
+
+	tests := []struct {
+		version string
+		content Content
+		want    Content
+	}{
+		{ProtocolVersion20251125, link, link},
+		{ProtocolVersion20250618, link, link},
+		{ProtocolVersion20250326, link, TextContent("Resource Containers at portainer://containers: All containers")},
+		{ProtocolVersion20241105, bareLink, TextContent("Resource prometheus://targets")},
+		{ProtocolVersion20250326, audio, audio},
+		{ProtocolVersion20241105, audio, TextContent("[audio/wav audio omitted: protocol version 2024-11-05 does not support audio content]")},
+		{ProtocolVersion20241105, text, text},
+	}
+
+	for _, tt := range tests {
+		if got := adaptContentBlock(tt.version, tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: got %+v, want %+v", tt.version, tt.content.Type, got, tt.want)
+		}
+	}
+}
+
+func TestAdaptResultsByVersion(t *testing.T) {
+	link := ResourceLink(Resource{URI: "portainer://containers", Name: "Containers"})
+	audio := AudioContent([]byte("RIFF"), "audio/wav")
+
+	s := NewServer("test", "1.0.0", LatestProtocolVersion)
+	content := []Content{link, audio}
+	s.RegisterTool(Tool{Name: "media", InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnly("Media")},
+		func(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+			return &CallToolResult{Content: content, StructuredContent: map[string]interface{}{"n": 1}}, nil
+		})
+	s.RegisterPrompt(Prompt{Name: "media"}, func(ctx context.Context, arguments map[string]string) (*GetPromptResult, error) {
+		return &GetPromptResult{Messages: []PromptMessage{{Role: "user", Content: link}, {Role: "user", Content: audio}}}, nil
+	})
+
+	linkText := TextContent("Resource Containers at portainer://containers")
+	audioText := TextContent("[audio/wav audio omitted: protocol version 2024-11-05 does not support audio content]")
+	tests := []struct {
+		version string
+		want    []Content
+	}{
+		{ProtocolVersion20251125, []Content{link, audio}},
+		{ProtocolVersion20250618, []Content{link, audio}},
+		{ProtocolVersion20250326, []Content{linkText, audio}},
+		{ProtocolVersion20241105, []Content{linkText, audioText}},
+	}
+
+	for _, tt := range tests {
+		sess := sessionAtVersion(t, s, tt.version)
+
+		req := &JSONRPCRequest{JSONRPC: "2.0", ID: float64(1), Method: "tools/call", Params: json.RawMessage(`{"name":"media"}`)}
+		resp := s.Dispatch(context.Background(), sess, req)
+		result, ok := resp.Result.(*CallToolResult)
+		if !ok {
+			t.Fatalf("%s: tools/call returned %+v", tt.version, resp)
+		}
+		if !reflect.DeepEqual(result.Content, tt.want) {
+			t.Errorf("%s: tool content %+v, want %+v", tt.version, result.Content, tt.want)
+		}
+		if wantStructured := tt.version >= versionStructuredContent; (result.StructuredContent != nil) != wantStructured {
+			t.Errorf("%s: structuredContent %v", tt.version, result.StructuredContent)
+		}
+
+		req = &JSONRPCRequest{JSONRPC: "2.0", ID: float64(2), Method: "prompts/get", Params: json.RawMessage(`{"name":"media"}`)}
+		resp = s.Dispatch(context.Background(), sess, req)
+		prompt, ok := resp.Result.(*GetPromptResult)
+		if !ok {
+			t.Fatalf("%s: prompts/get returned %+v", tt.version, resp)
+		}
+		var got []Content
+		for _, msg := range prompt.Messages {
+			got = append(got, msg.Content)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: prompt content %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+
+	// The handler's content is not modified
+	if !reflect.DeepEqual(content, []Content{link, audio}) {
+		t.Errorf("handler content modified: %+v", content)
+	}
+}
@@ -39,7 +39,7 @@ var (
 			Name: "mcp_rpc_requests_total",
 			Help: "Total number of RPC requests handled",
 		},
-		[]string{"method", "transport"}, // transport: stdio or http
+		[]string{"method", "transport"}, // transport: stdio, http or streamable-http
 	)
 
 	// RPC request duration
@@ -61,6 +61,52 @@ var (
 		[]string{"method", "error_code", "transport"},
 	)
 
+	// Requests currently being handled
+	InFlightRequests = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_rpc_in_flight_requests",
+			Help: "Number of RPC requests currently being handled",
+		},
+		[]string{"transport"},
+	)
+
+	// Requests refused by a rate limit or concurrency cap
+	RateLimitedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_rate_limited_total",
+			Help: "Total number of requests refused by a rate limit or concurrency cap",
+		},
+		[]string{"limit", "name"}, // limit: caller, tool or backend
+	)
+
+	// Backend requests currently in flight
+	BackendInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_backend_in_flight_requests",
+			Help: "Number of requests in flight to each backend",
+		},
+		[]string{"backend"},
+	)
+
+	// Backend concurrency caps
+	BackendConcurrencyLimit = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_backend_concurrency_limit",
+			Help: "Maximum number of requests in flight to each backend (0: no cap)",
+		},
+		[]string{"backend"},
+	)
+
+	// Time spent waiting for a backend below its cap
+	BackendWaitDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mcp_backend_wait_seconds",
+			Help:    "Time requests waited for a backend below its concurrency cap",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"backend"},
+	)
+
 	// Active connections (for HTTP mode)
 	ActiveConnections = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "mcp_http_active_connections",
@@ -87,3 +133,8 @@ func RecordRPCRequest(method, transport string, duration time.Duration, errCode
 		RPCErrorsTotal.WithLabelValues(method, errCode, transport).Inc()
 	}
 }
+
+// RecordRateLimited records a request refused by a limit of the given kind
+func RecordRateLimited(limit, name string) {
+	RateLimitedTotal.WithLabelValues(limit, name).Inc()
+}
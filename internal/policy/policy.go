@@ -0,0 +1,381 @@
+// Package policy decides which tool calls may run, from rules in a YAML
+// file:
+//
+//	default: allow
+//	rules:
+//	  - name: restart-managed-containers
+//	    tools: [portainer_restart_container, portainer_stop_container]
+//	    attributes:
+//	      label.axinova.managed: "true"
+//	    effect: allow
+//	  - name: restart-other-containers
+//	    tools: [portainer_restart_container, portainer_stop_container]
+//	    effect: deny
+//	    reason: only containers labelled axinova.managed=true may be restarted
+//	  - name: delete-dashboards-in-business-hours
+//	    tools: [grafana_delete_dashboard]
+//	    schedule: {days: [mon, tue, wed, thu, fri], hours: "09:00-18:00", timezone: Europe/Berlin}
+//	    effect: allow
+//	  - name: delete-dashboards
+//	    tools: [grafana_delete_dashboard]
+//	    effect: deny
+//	    reason: dashboards may only be deleted in business hours
+//
+// The first rule that matches a call decides whether it runs; calls no rule
+// matches get the default effect. A rule matches when all of its
+// conditions do:
+//
+//   - callers: the caller's identity, "token <name>" for an API token,
+//     "oauth <issuer> <subject>" for an OAuth subject, or local for stdio
+//   - tools: the tool name
+//   - arguments: tool argument values by argument name; a missing argument
+//     is empty
+//   - attributes: attributes of what the call acts on, such as
+//     label.<key> of a Portainer container, by attribute name
+//   - schedule: the days and hours of the week the rule is in force
+//
+// Values are patterns in which * matches any run of characters. Lists
+// match when any of their patterns does.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// Effects of a rule
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Policy is a compiled policy file. It implements mcp.ToolPolicy.
+type Policy struct {
+	defaultEffect string
+	rules         []*rule
+
+	now func() time.Time
+}
+
+// Denial is the error returned for a call the policy does not allow
+type Denial struct {
+	Rule   string // name of the rule that denied the call, or default
+	Caller string // identity of the caller, as auth.CallerIdentity
+	Reason string
+}
+
+func (d *Denial) Error() string {
+	if d.Reason == "" {
+		return fmt.Sprintf("denied by policy rule %s", d.Rule)
+	}
+	return fmt.Sprintf("denied by policy rule %s: %s", d.Rule, d.Reason)
+}
+
+// LogValue logs the rule and caller along with the reason
+func (d *Denial) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("rule", d.Rule),
+		slog.String("caller", d.Caller),
+		slog.String("reason", d.Reason),
+	)
+}
+
+type fileConfig struct {
+	Default string       `koanf:"default"`
+	Rules   []ruleConfig `koanf:"rules"`
+}
+
+type ruleConfig struct {
+	Name       string            `koanf:"name"`
+	Effect     string            `koanf:"effect"`
+	Reason     string            `koanf:"reason"`
+	Callers    []string          `koanf:"callers"`
+	Tools      []string          `koanf:"tools"`
+	Arguments  map[string]string `koanf:"arguments"`
+	Attributes map[string]string `koanf:"attributes"`
+	Schedule   *scheduleConfig   `koanf:"schedule"`
+}
+
+type scheduleConfig struct {
+	Days     []string `koanf:"days"`
+	Hours    string   `koanf:"hours"`
+	Timezone string   `koanf:"timezone"`
+}
+
+type rule struct {
+	name       string
+	effect     string
+	reason     string
+	callers    patterns
+	tools      patterns
+	arguments  map[string]*regexp.Regexp
+	attributes map[string]*regexp.Regexp
+	schedule   *schedule
+}
+
+// Load reads and compiles a policy file
+func Load(path string) (*Policy, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+	var cfg fileConfig
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+
+	p, err := compile(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("policy file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func compile(cfg fileConfig) (*Policy, error) {
+	p := &Policy{defaultEffect: cfg.Default, now: time.Now}
+	if p.defaultEffect == "" {
+		p.defaultEffect = Allow
+	}
+	if p.defaultEffect != Allow && p.defaultEffect != Deny {
+		return nil, fmt.Errorf("default must be allow or deny, not %q", cfg.Default)
+	}
+
+	names := make(map[string]bool, len(cfg.Rules))
+	for i, rc := range cfg.Rules {
+		r := &rule{name: rc.Name, effect: rc.Effect, reason: rc.Reason}
+		if r.name == "" {
+			r.name = fmt.Sprintf("#%d", i+1)
+		}
+		if names[r.name] {
+			return nil, fmt.Errorf("rule %s is defined twice", r.name)
+		}
+		names[r.name] = true
+
+		if r.effect != Allow && r.effect != Deny {
+			return nil, fmt.Errorf("rule %s: effect must be allow or deny, not %q", r.name, rc.Effect)
+		}
+		r.callers = compilePatterns(rc.Callers)
+		r.tools = compilePatterns(rc.Tools)
+		r.arguments = compilePatternMap(rc.Arguments)
+		r.attributes = compilePatternMap(rc.Attributes)
+		if rc.Schedule != nil {
+			s, err := compileSchedule(*rc.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.name, err)
+			}
+			r.schedule = s
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// Len returns how many rules the policy has
+func (p *Policy) Len() int {
+	return len(p.rules)
+}
+
+// CheckToolCall implements mcp.ToolPolicy. The call's attributes are only
+// looked up when a rule needs them; if that fails, the call is denied.
+func (p *Policy) CheckToolCall(ctx context.Context, call mcp.ToolCall) error {
+	caller := auth.CallerIdentity(ctx)
+	now := p.now()
+
+	var attributes map[string]string
+	var attributesErr error
+	attributesLoaded := false
+	lookup := func() (map[string]string, error) {
+		if !attributesLoaded {
+			attributes, attributesErr = call.Attributes(ctx)
+			attributesLoaded = true
+		}
+		return attributes, attributesErr
+	}
+
+	for _, r := range p.rules {
+		matched, err := r.matches(caller, call, lookup, now)
+		if err != nil {
+			return &Denial{Rule: r.name, Caller: caller, Reason: fmt.Sprintf("could not check %s: %v", call.Tool.Name, err)}
+		}
+		if !matched {
+			continue
+		}
+		if r.effect == Deny {
+			return &Denial{Rule: r.name, Caller: caller, Reason: r.reason}
+		}
+		return nil
+	}
+
+	if p.defaultEffect == Deny {
+		return &Denial{Rule: "default", Caller: caller, Reason: "no rule allows it"}
+	}
+	return nil
+}
+
+func (r *rule) matches(caller string, call mcp.ToolCall, attributes func() (map[string]string, error), now time.Time) (bool, error) {
+	if !r.callers.match(caller) || !r.tools.match(call.Tool.Name) {
+		return false, nil
+	}
+	if r.schedule != nil && !r.schedule.contains(now) {
+		return false, nil
+	}
+	for name, pattern := range r.arguments {
+		if !pattern.MatchString(argumentString(call.Arguments[name])) {
+			return false, nil
+		}
+	}
+	if len(r.attributes) == 0 {
+		return true, nil
+	}
+
+	attrs, err := attributes()
+	if err != nil {
+		return false, err
+	}
+	for name, pattern := range r.attributes {
+		if !pattern.MatchString(attrs[name]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// argumentString renders an argument value for matching
+func argumentString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// patterns matches a value against any of its patterns; empty patterns
+// match everything
+type patterns []*regexp.Regexp
+
+func (ps patterns) match(s string) bool {
+	if len(ps) == 0 {
+		return true
+	}
+	for _, p := range ps {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func compilePatterns(values []string) patterns {
+	ps := make(patterns, 0, len(values))
+	for _, v := range values {
+		ps = append(ps, compilePattern(v))
+	}
+	return ps
+}
+
+func compilePatternMap(values map[string]string) map[string]*regexp.Regexp {
+	ps := make(map[string]*regexp.Regexp, len(values))
+	for k, v := range values {
+		ps[k] = compilePattern(v)
+	}
+	return ps
+}
+
+// compilePattern turns a pattern in which * matches any run of characters
+// into an anchored regular expression
+func compilePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// schedule is a weekly time window
+type schedule struct {
+	days       map[time.Weekday]bool // nil for every day
+	start, end int                   // minutes after midnight; end before start spans midnight
+	location   *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func compileSchedule(cfg scheduleConfig) (*schedule, error) {
+	s := &schedule{end: 24 * 60, location: time.Local}
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("schedule timezone: %w", err)
+		}
+		s.location = loc
+	}
+
+	if len(cfg.Days) > 0 {
+		s.days = make(map[time.Weekday]bool, len(cfg.Days))
+		for _, d := range cfg.Days {
+			day, ok := weekdays[strings.ToLower(d)]
+			if !ok {
+				return nil, fmt.Errorf("schedule day %q, want mon, tue, wed, thu, fri, sat or sun", d)
+			}
+			s.days[day] = true
+		}
+	}
+
+	if cfg.Hours != "" {
+		from, to, ok := strings.Cut(cfg.Hours, "-")
+		start, err1 := parseClock(from)
+		end, err2 := parseClock(to)
+		if !ok || errors.Join(err1, err2) != nil || start == end {
+			return nil, fmt.Errorf("schedule hours %q, want HH:MM-HH:MM", cfg.Hours)
+		}
+		s.start, s.end = start, end
+	}
+	return s, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight. 24:00 is the end
+// of the day.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		if strings.TrimSpace(s) == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports whether t falls in the window. A window spanning
+// midnight belongs to the day it starts on.
+func (s *schedule) contains(t time.Time) bool {
+	t = t.In(s.location)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if s.start < s.end {
+		return s.onDay(day) && minute >= s.start && minute < s.end
+	}
+	if minute >= s.start {
+		return s.onDay(day)
+	}
+	return minute < s.end && s.onDay((day+6)%7)
+}
+
+func (s *schedule) onDay(day time.Weekday) bool {
+	return s.days == nil || s.days[day]
+}
@@ -0,0 +1,190 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+const testPolicy = `
+default: deny
+rules:
+  - name: ops-anything
+    callers: ["token ops-*", "oauth https://auth.example.com ops-*"]
+    effect: allow
+  - name: local-starts
+    callers: [local]
+    tools: [portainer_start_container]
+    effect: allow
+  - name: no-prod-restarts
+    tools: [portainer_restart_container, portainer_stop_container]
+    arguments:
+      container_id: "prod-*"
+    effect: deny
+    reason: production containers are restarted by the on-call engineer
+  - name: managed-restarts
+    tools: [portainer_restart_container]
+    attributes:
+      label.axinova.managed: "true"
+    effect: allow
+  - name: business-hours-deletes
+    tools: [grafana_delete_dashboard]
+    schedule: {days: [mon, tue, wed, thu, fri], hours: "09:00-18:00", timezone: UTC}
+    effect: allow
+  - name: reads
+    tools: ["*_list_*", "*_get_*"]
+    effect: allow
+`
+
+func loadTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return p
+}
+
+func TestCheckToolCall(t *testing.T) {
+	p := loadTestPolicy(t)
+	if got := p.Len(); got != 6 {
+		t.Fatalf("Len() = %d, want 6", got)
+	}
+
+	// Wednesday 10:00 and 20:00 UTC
+	inHours := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	afterHours := time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC)
+
+	ops := &auth.Principal{Name: "ops-alice", Scopes: []string{"*"}}
+	dev := &auth.Principal{Name: "dev-bob", Scopes: []string{"*"}}
+	opsOAuth := &auth.Principal{Name: "ops-alice", Issuer: "https://auth.example.com", Scopes: []string{"*"}}
+	opsOtherIssuer := &auth.Principal{Name: "ops-alice", Issuer: "https://evil.example.com", Scopes: []string{"*"}}
+	localOAuth := &auth.Principal{Name: auth.LocalCaller, Issuer: "https://auth.example.com", Scopes: []string{"*"}}
+
+	tests := []struct {
+		name      string
+		caller    *auth.Principal // nil for a local caller
+		tool      string
+		arguments map[string]interface{}
+		now       time.Time
+		wantRule  string // empty when the call is allowed
+	}{
+		{"caller pattern", ops, "grafana_delete_dashboard", nil, afterHours, ""},
+		{"caller pattern does not match", dev, "vikunja_delete_task", nil, inHours, "default"},
+		{"OAuth caller pattern", opsOAuth, "vikunja_delete_task", nil, inHours, ""},
+		{"OAuth caller of other issuer", opsOtherIssuer, "vikunja_delete_task", nil, inHours, "default"},
+		{"argument pattern", dev, "portainer_stop_container", map[string]interface{}{"container_id": "prod-db"}, inHours, "no-prod-restarts"},
+		{"argument pattern does not match", dev, "portainer_stop_container", map[string]interface{}{"container_id": "test-db"}, inHours, "default"},
+		{"missing argument", dev, "portainer_stop_container", nil, inHours, "default"},
+		{"missing attributes", dev, "portainer_restart_container", map[string]interface{}{"container_id": "web"}, inHours, "default"},
+		{"in schedule", dev, "grafana_delete_dashboard", nil, inHours, ""},
+		{"out of schedule", dev, "grafana_delete_dashboard", nil, afterHours, "default"},
+		{"tool pattern", dev, "prometheus_list_alerts", nil, afterHours, ""},
+		{"local caller", nil, "portainer_get_container_logs", nil, afterHours, ""},
+		{"local caller rule", nil, "portainer_start_container", nil, afterHours, ""},
+		{"local caller is not ops", nil, "vikunja_delete_task", nil, afterHours, "default"},
+		{"OAuth subject named local", localOAuth, "portainer_start_container", nil, afterHours, "default"},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		wantCaller := auth.LocalCaller
+		if tt.caller != nil {
+			ctx = mcp.WithAuthorizer(ctx, tt.caller)
+			wantCaller = tt.caller.Identity()
+		}
+		p.now = func() time.Time { return tt.now }
+
+		err := p.CheckToolCall(ctx, mcp.ToolCall{Tool: mcp.Tool{Name: tt.tool}, Arguments: tt.arguments})
+		if tt.wantRule == "" {
+			if err != nil {
+				t.Errorf("%s: CheckToolCall = %v, want allowed", tt.name, err)
+			}
+			continue
+		}
+
+		var denial *Denial
+		if !errors.As(err, &denial) {
+			t.Errorf("%s: CheckToolCall = %v, want denial by %s", tt.name, err, tt.wantRule)
+			continue
+		}
+		if denial.Rule != tt.wantRule || denial.Caller != wantCaller {
+			t.Errorf("%s: denied by %s for %s, want %s for %s", tt.name, denial.Rule, denial.Caller, tt.wantRule, wantCaller)
+		}
+	}
+}
+
+func TestScheduleContains(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  scheduleConfig
+		at   time.Time
+		want bool
+	}{
+		{"whole day", scheduleConfig{Days: []string{"sat"}}, time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC), true},
+		{"other day", scheduleConfig{Days: []string{"sat"}}, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), false},
+		{"every day", scheduleConfig{Hours: "09:00-17:00"}, time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC), true},
+		{"start is inclusive", scheduleConfig{Hours: "09:00-17:00"}, time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), true},
+		{"end is exclusive", scheduleConfig{Hours: "09:00-17:00"}, time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC), false},
+		{"until end of day", scheduleConfig{Hours: "18:00-24:00"}, time.Date(2026, 10, 14, 23, 59, 0, 0, time.UTC), true},
+		{"day names are case insensitive", scheduleConfig{Days: []string{"Wed"}}, time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), true},
+
+		// Friday 22:00 to Saturday 06:00 belongs to Friday
+		{"overnight before midnight", scheduleConfig{Days: []string{"fri"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), true},
+		{"overnight after midnight", scheduleConfig{Days: []string{"fri"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 17, 5, 59, 0, 0, time.UTC), true},
+		{"overnight end", scheduleConfig{Days: []string{"fri"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC), false},
+		{"overnight gap", scheduleConfig{Days: []string{"fri"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), false},
+		{"overnight from previous day", scheduleConfig{Days: []string{"fri"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 16, 5, 0, 0, 0, time.UTC), false},
+		{"overnight into sunday", scheduleConfig{Days: []string{"sat"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC), true},
+		{"overnight into monday", scheduleConfig{Days: []string{"sun"}, Hours: "22:00-06:00"}, time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC), true},
+
+		// 09:00-17:00 in Tokyo is 00:00-08:00 UTC
+		{"timezone", scheduleConfig{Hours: "09:00-17:00", Timezone: "Asia/Tokyo"}, time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC), true},
+		{"timezone outside", scheduleConfig{Hours: "09:00-17:00", Timezone: "Asia/Tokyo"}, time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if tt.cfg.Timezone == "" {
+			tt.cfg.Timezone = "UTC"
+		}
+		s, err := compileSchedule(tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: compileSchedule: %v", tt.name, err)
+		}
+		if got := s.contains(tt.at); got != tt.want {
+			t.Errorf("%s: contains(%s) = %v, want %v", tt.name, tt.at.Format(time.RFC3339), got, tt.want)
+		}
+	}
+}
+
+func TestCompileRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  fileConfig
+	}{
+		{"invalid default", fileConfig{Default: "maybe"}},
+		{"invalid effect", fileConfig{Rules: []ruleConfig{{Name: "a", Effect: "permit"}}}},
+		{"missing effect", fileConfig{Rules: []ruleConfig{{Name: "a"}}}},
+		{"duplicate rule", fileConfig{Rules: []ruleConfig{{Name: "a", Effect: Allow}, {Name: "a", Effect: Deny}}}},
+		{"invalid day", fileConfig{Rules: []ruleConfig{{Effect: Allow, Schedule: &scheduleConfig{Days: []string{"monday"}}}}}},
+		{"invalid hours", fileConfig{Rules: []ruleConfig{{Effect: Allow, Schedule: &scheduleConfig{Hours: "9-17"}}}}},
+		{"empty hours", fileConfig{Rules: []ruleConfig{{Effect: Allow, Schedule: &scheduleConfig{Hours: "09:00-09:00"}}}}},
+		{"invalid timezone", fileConfig{Rules: []ruleConfig{{Effect: Allow, Schedule: &scheduleConfig{Timezone: "Mars/Olympus"}}}}},
+	}
+
+	for _, tt := range tests {
+		if _, err := compile(tt.cfg); err == nil {
+			t.Errorf("%s: compile succeeded, want error", tt.name)
+		}
+	}
+}
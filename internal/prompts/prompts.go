@@ -0,0 +1,252 @@
+// Package prompts loads MCP prompt templates from YAML files.
+//
+// Each file defines one prompt:
+//
+//	name: investigate_container
+//	description: Investigate an unhealthy container
+//	arguments:
+//	  - name: container_id
+//	    description: Container ID or name
+//	    required: true
+//	messages:
+//	  - role: user
+//	    text: |
+//	      Container {{.container_id}} is misbehaving. ...
+//	  - role: user
+//	    resource: portainer://endpoint/1/container/{{pathescape .container_id}}/logs
+//
+// Message text and resource URIs are Go text/template templates executed
+// with the prompt arguments. Resource messages are read through the server
+// and embedded in the prompt. Resource URIs must start with a literal
+// scheme, which names the backend callers need access to for the prompt.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// Template is a prompt loaded from a YAML file
+type Template struct {
+	Name        string     `koanf:"name"`
+	Description string     `koanf:"description"`
+	Arguments   []Argument `koanf:"arguments"`
+	Messages    []Message  `koanf:"messages"`
+}
+
+// Argument is an argument a prompt accepts
+type Argument struct {
+	Name        string `koanf:"name"`
+	Description string `koanf:"description"`
+	Required    bool   `koanf:"required"`
+}
+
+// Message is one message of a prompt. Exactly one of Text and Resource is
+// set; Resource is the URI of a resource to embed.
+type Message struct {
+	Role     string `koanf:"role"`
+	Text     string `koanf:"text"`
+	Resource string `koanf:"resource"`
+
+	tmpl *template.Template
+}
+
+var funcs = template.FuncMap{
+	// default returns value, or def if value is empty
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+	"pathescape": url.PathEscape,
+}
+
+// LoadDir loads every *.yaml file in dir, sorted by file name
+func LoadDir(dir string) ([]*Template, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("prompts directory: %w", err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	templates := make([]*Template, 0, len(paths))
+	seen := make(map[string]string, len(paths))
+	for _, path := range paths {
+		t, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("%s: prompt %q already defined in %s", path, t.Name, prev)
+		}
+		seen[t.Name] = path
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Load loads and compiles a prompt from a YAML file
+func Load(path string) (*Template, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var t Template
+	if err := k.Unmarshal("", &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := t.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// compile validates the prompt and parses its message templates
+func (t *Template) compile() error {
+	if t.Name == "" {
+		return fmt.Errorf("prompt has no name")
+	}
+	if len(t.Messages) == 0 {
+		return fmt.Errorf("prompt %q has no messages", t.Name)
+	}
+
+	for i, arg := range t.Arguments {
+		if arg.Name == "" {
+			return fmt.Errorf("prompt %q: argument %d has no name", t.Name, i)
+		}
+	}
+
+	for i := range t.Messages {
+		m := &t.Messages[i]
+		if m.Role != "user" && m.Role != "assistant" {
+			return fmt.Errorf("prompt %q: message %d: role must be user or assistant, got %q", t.Name, i, m.Role)
+		}
+		if (m.Text == "") == (m.Resource == "") {
+			return fmt.Errorf("prompt %q: message %d: exactly one of text and resource must be set", t.Name, i)
+		}
+
+		source := m.Text
+		if m.Resource != "" {
+			source = m.Resource
+			if _, err := m.service(); err != nil {
+				return fmt.Errorf("prompt %q: message %d: %w", t.Name, i, err)
+			}
+		}
+		tmpl, err := template.New(fmt.Sprintf("%s[%d]", t.Name, i)).
+			Option("missingkey=zero").
+			Funcs(funcs).
+			Parse(source)
+		if err != nil {
+			return fmt.Errorf("prompt %q: message %d: %w", t.Name, i, err)
+		}
+		m.tmpl = tmpl
+	}
+	return nil
+}
+
+// service returns the scheme of a resource message's URI
+func (m *Message) service() (string, error) {
+	scheme, _, ok := strings.Cut(m.Resource, "://")
+	if !ok || scheme == "" || strings.Contains(scheme, "{{") {
+		return "", fmt.Errorf("resource %q does not start with a literal scheme", m.Resource)
+	}
+	return scheme, nil
+}
+
+// Prompt returns the prompt as advertised by prompts/list
+func (t *Template) Prompt() mcp.Prompt {
+	prompt := mcp.Prompt{
+		Name:        t.Name,
+		Description: t.Description,
+	}
+	for _, m := range t.Messages {
+		if m.Resource == "" {
+			continue
+		}
+		service, _ := m.service()
+		if !slices.Contains(prompt.Services, service) {
+			prompt.Services = append(prompt.Services, service)
+		}
+	}
+	for _, arg := range t.Arguments {
+		prompt.Arguments = append(prompt.Arguments, mcp.PromptArgument{
+			Name:        arg.Name,
+			Description: arg.Description,
+			Required:    arg.Required,
+		})
+	}
+	return prompt
+}
+
+// Render executes the prompt's messages with args. Resources are read
+// through server; a resource that cannot be read is linked instead.
+func (t *Template) Render(ctx context.Context, server *mcp.Server, args map[string]string) (*mcp.GetPromptResult, error) {
+	// Declared arguments that were not supplied render as empty strings
+	data := make(map[string]string, len(t.Arguments))
+	for _, arg := range t.Arguments {
+		data[arg.Name] = ""
+	}
+	for name, value := range args {
+		data[name] = value
+	}
+
+	result := &mcp.GetPromptResult{Description: t.Description}
+	for _, m := range t.Messages {
+		var b strings.Builder
+		if err := m.tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render prompt: %w", err)
+		}
+
+		content := mcp.TextContent(strings.TrimSpace(b.String()))
+		if m.Resource != "" {
+			content = embedResource(ctx, server, strings.TrimSpace(b.String()))
+		}
+		result.Messages = append(result.Messages, mcp.PromptMessage{
+			Role:    m.Role,
+			Content: content,
+		})
+	}
+	return result, nil
+}
+
+// embedResource reads uri and embeds it, falling back to a link so that
+// the prompt still renders when a backend is unavailable
+func embedResource(ctx context.Context, server *mcp.Server, uri string) mcp.Content {
+	contents, err := server.ReadResource(ctx, uri)
+	if err != nil {
+		return mcp.ResourceLink(mcp.Resource{
+			URI:         uri,
+			Name:        uri,
+			Description: fmt.Sprintf("Could not be read: %v", err),
+		})
+	}
+	return mcp.EmbeddedResource(*contents)
+}
+
+// Register registers each template as a prompt on server
+func Register(server *mcp.Server, templates []*Template) {
+	for _, t := range templates {
+		t := t
+		server.RegisterPrompt(t.Prompt(), func(ctx context.Context, args map[string]string) (*mcp.GetPromptResult, error) {
+			return t.Render(ctx, server, args)
+		})
+	}
+}
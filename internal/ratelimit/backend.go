@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/metrics"
+)
+
+const (
+	// defaultBackendWait is how long a request waits for a backend at its
+	// cap when no wait is configured
+	defaultBackendWait = 5 * time.Second
+
+	// backendRetryAfter is suggested to clients refused by a backend cap
+	backendRetryAfter = time.Second
+)
+
+// Backends caps the requests in flight to each backend. The caps can be
+// changed while requests are in flight.
+type Backends struct {
+	mu          sync.Mutex
+	concurrency int
+	overrides   map[string]int
+	wait        time.Duration
+	slots       map[string]chan struct{} // nil for backends without a cap
+}
+
+// NewBackends creates backend caps for cfg
+func NewBackends(cfg config.LimitsConfig) *Backends {
+	b := &Backends{}
+	b.SetLimits(cfg)
+	return b
+}
+
+// SetLimits replaces the caps. Backends whose cap changes start over with
+// no requests counted; requests in flight to them release the slots they
+// took from the old cap.
+func (b *Backends) SetLimits(cfg config.LimitsConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.concurrency = cfg.BackendConcurrency
+	b.overrides = cfg.Backends
+	b.wait = cfg.BackendWait
+	if b.wait <= 0 {
+		b.wait = defaultBackendWait
+	}
+
+	slots := make(map[string]chan struct{}, len(b.slots))
+	for backend, old := range b.slots {
+		if cap(old) == b.limit(backend) {
+			slots[backend] = old
+		}
+	}
+	b.slots = slots
+}
+
+// limit returns the cap of backend, 0 for none
+func (b *Backends) limit(backend string) int {
+	if limit, ok := b.overrides[backend]; ok {
+		return limit
+	}
+	return b.concurrency
+}
+
+// slotsFor returns the slots of backend, or nil when it has no cap
+func (b *Backends) slotsFor(backend string) (chan struct{}, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	slots, ok := b.slots[backend]
+	if !ok {
+		limit := b.limit(backend)
+		if limit > 0 {
+			slots = make(chan struct{}, limit)
+		}
+		b.slots[backend] = slots
+		metrics.BackendConcurrencyLimit.WithLabelValues(backend).Set(float64(limit))
+	}
+	return slots, b.wait
+}
+
+// Transport returns a function wrapping the transport of a backend's HTTP
+// client, for the clients' WrapTransport
+func (b *Backends) Transport(backend string) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &limitedTransport{backends: b, backend: backend, next: next}
+	}
+}
+
+// limitedTransport waits for a free slot of its backend before each
+// request. Requests that cannot get one in time fail with a
+// *mcp.RateLimitError.
+type limitedTransport struct {
+	backends *Backends
+	backend  string
+	next     http.RoundTripper
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	slots, wait := t.backends.slotsFor(t.backend)
+	if slots == nil {
+		return t.track(req)
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+	case <-timer.C:
+		metrics.RecordRateLimited(LimitBackend, t.backend)
+		return nil, &mcp.RateLimitError{Limit: LimitBackend + " " + t.backend, RetryAfter: backendRetryAfter}
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	metrics.BackendWaitDuration.WithLabelValues(t.backend).Observe(time.Since(start).Seconds())
+
+	// The slot is held until the body is read, since the backend is still
+	// busy sending it
+	resp, err := t.track(req)
+	if err != nil {
+		<-slots
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-slots }}
+	return resp, nil
+}
+
+func (t *limitedTransport) track(req *http.Request) (*http.Response, error) {
+	inFlight := metrics.BackendInFlight.WithLabelValues(t.backend)
+	inFlight.Inc()
+	defer inFlight.Dec()
+	return t.next.RoundTrip(req)
+}
+
+// releasingBody frees a backend slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,135 @@
+// Package ratelimit limits tool calls with token buckets per caller and per
+// tool, and caps the requests in flight to each backend.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/metrics"
+)
+
+// bucketSweepInterval is how often buckets that have refilled are dropped,
+// so that the buckets of callers seen once do not accumulate
+const bucketSweepInterval = time.Minute
+
+// Kinds of limits, as reported in errors and metrics
+const (
+	LimitCaller  = "caller"
+	LimitTool    = "tool"
+	LimitBackend = "backend"
+)
+
+// Limiter limits tool calls per caller and per tool. It implements
+// mcp.ToolCallLimiter.
+type Limiter struct {
+	callers buckets
+	tools   buckets
+}
+
+// New creates a limiter for cfg
+func New(cfg config.LimitsConfig) *Limiter {
+	return &Limiter{
+		callers: newBuckets(cfg.PerCaller, cfg.Callers),
+		tools:   newBuckets(cfg.PerTool, cfg.Tools),
+	}
+}
+
+// LimitToolCall implements mcp.ToolCallLimiter. A call takes a token from
+// its caller's bucket, found by the caller's identity, and from its
+// tool's; when either is empty, neither is taken.
+func (l *Limiter) LimitToolCall(ctx context.Context, call mcp.ToolCall) error {
+	caller := auth.CallerIdentity(ctx)
+	now := time.Now()
+
+	callerRes := l.callers.reserve(caller, now)
+	if err := check(callerRes, LimitCaller, caller, now); err != nil {
+		return err
+	}
+	toolRes := l.tools.reserve(call.Tool.Name, now)
+	if err := check(toolRes, LimitTool, call.Tool.Name, now); err != nil {
+		if callerRes != nil {
+			callerRes.CancelAt(now)
+		}
+		return err
+	}
+	return nil
+}
+
+// check returns a *mcp.RateLimitError, cancelling res, when res has to
+// wait for a token
+func check(res *rate.Reservation, limit, name string, now time.Time) error {
+	if res == nil {
+		return nil
+	}
+	delay := res.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	res.CancelAt(now)
+	metrics.RecordRateLimited(limit, name)
+	return &mcp.RateLimitError{Limit: limit + " " + name, RetryAfter: delay}
+}
+
+// buckets are the token buckets of one kind of limit, created on first use
+// and dropped once full again, since a full bucket is the same as a new
+// one
+type buckets struct {
+	defaults  config.RateConfig
+	overrides map[string]config.RateConfig
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter // nil for names without a limit
+	swept   time.Time
+}
+
+func newBuckets(defaults config.RateConfig, overrides map[string]config.RateConfig) buckets {
+	return buckets{
+		defaults:  defaults,
+		overrides: overrides,
+		buckets:   make(map[string]*rate.Limiter),
+	}
+}
+
+// reserve takes a token from the bucket of name, or returns nil when name
+// is not limited
+func (b *buckets) reserve(name string, now time.Time) *rate.Reservation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Sub(b.swept) >= bucketSweepInterval {
+		b.sweep(now)
+	}
+
+	limiter, ok := b.buckets[name]
+	if !ok {
+		cfg, ok := b.overrides[name]
+		if !ok {
+			cfg = b.defaults
+		}
+		if cfg.PerMinute > 0 {
+			limiter = rate.NewLimiter(rate.Limit(cfg.PerMinute/60), max(cfg.Burst, 1))
+		}
+		b.buckets[name] = limiter
+	}
+	if limiter == nil {
+		return nil
+	}
+	return limiter.ReserveN(now, 1)
+}
+
+// sweep drops the buckets that are full. The caller must hold mu.
+func (b *buckets) sweep(now time.Time) {
+	for name, limiter := range b.buckets {
+		if limiter == nil || limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(b.buckets, name)
+		}
+	}
+	b.swept = now
+}
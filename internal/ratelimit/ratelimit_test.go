@@ -0,0 +1,214 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/auth"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/config"
+	"github.com/axinova-ai/axinova-mcp-server-go/internal/mcp"
+)
+
+// callerContext returns the context of a call by the API token name, or
+// by an OAuth subject when name is issuer/subject
+func callerContext(name string) context.Context {
+	p := &auth.Principal{Name: name, Scopes: []string{"*"}}
+	if issuer, subject, ok := strings.Cut(name, "/"); ok {
+		p.Issuer, p.Name = "https://"+issuer, subject
+	}
+	return mcp.WithAuthorizer(context.Background(), p)
+}
+
+func toolCall(name string) mcp.ToolCall {
+	return mcp.ToolCall{Tool: mcp.Tool{Name: name}}
+}
+
+func TestLimitToolCall(t *testing.T) {
+	type call struct {
+		caller string // token name or issuer/subject, empty for a local caller
+		tool   string
+		limit  string // the limit exceeded, empty when the call may run
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.LimitsConfig
+		calls []call
+	}{
+		{
+			name: "no limits",
+			calls: []call{
+				{"alice", "portainer_list_containers", ""},
+				{"alice", "portainer_list_containers", ""},
+			},
+		},
+		{
+			name: "caller burst",
+			cfg:  config.LimitsConfig{PerCaller: config.RateConfig{PerMinute: 1, Burst: 2}},
+			calls: []call{
+				{"alice", "portainer_list_containers", ""},
+				{"alice", "grafana_list_dashboards", ""},
+				{"alice", "vikunja_list_tasks", "caller token alice"},
+				{"bob", "vikunja_list_tasks", ""},
+				{"", "vikunja_list_tasks", ""},
+			},
+		},
+		{
+			name: "caller override",
+			cfg: config.LimitsConfig{
+				PerCaller: config.RateConfig{PerMinute: 1, Burst: 1},
+				Callers:   map[string]config.RateConfig{"token ci": {PerMinute: 60, Burst: 3}, "token admin": {}},
+			},
+			calls: []call{
+				{"ci", "portainer_list_containers", ""},
+				{"ci", "portainer_list_containers", ""},
+				{"ci", "portainer_list_containers", ""},
+				{"ci", "portainer_list_containers", "caller token ci"},
+				{"admin", "portainer_list_containers", ""},
+				{"admin", "portainer_list_containers", ""},
+				{"alice", "portainer_list_containers", ""},
+				{"alice", "portainer_list_containers", "caller token alice"},
+			},
+		},
+		{
+			// An OAuth subject and a token of the same name are different
+			// callers, and neither is the local caller
+			name: "callers by identity",
+			cfg: config.LimitsConfig{
+				PerCaller: config.RateConfig{PerMinute: 1, Burst: 1},
+				Callers:   map[string]config.RateConfig{"token ci": {}},
+			},
+			calls: []call{
+				{"ci", "portainer_list_containers", ""},
+				{"ci", "portainer_list_containers", ""},
+				{"auth.example.com/ci", "portainer_list_containers", ""},
+				{"auth.example.com/ci", "portainer_list_containers", "caller oauth https://auth.example.com ci"},
+				{"other.example.com/ci", "portainer_list_containers", ""},
+				{"auth.example.com/local", "portainer_list_containers", ""},
+				{"", "portainer_list_containers", ""},
+				{"", "portainer_list_containers", "caller local"},
+			},
+		},
+		{
+			name: "zero burst allows one call",
+			cfg:  config.LimitsConfig{PerCaller: config.RateConfig{PerMinute: 1}},
+			calls: []call{
+				{"alice", "portainer_list_containers", ""},
+				{"alice", "portainer_list_containers", "caller token alice"},
+			},
+		},
+		{
+			name: "tool limit across callers",
+			cfg:  config.LimitsConfig{Tools: map[string]config.RateConfig{"portainer_restart_container": {PerMinute: 1, Burst: 1}}},
+			calls: []call{
+				{"alice", "portainer_restart_container", ""},
+				{"bob", "portainer_restart_container", "tool portainer_restart_container"},
+				{"bob", "portainer_stop_container", ""},
+			},
+		},
+		{
+			// A call refused by its tool limit does not use up its caller's
+			// token
+			name: "tool limit keeps caller token",
+			cfg: config.LimitsConfig{
+				PerCaller: config.RateConfig{PerMinute: 1, Burst: 2},
+				PerTool:   config.RateConfig{PerMinute: 1, Burst: 1},
+			},
+			calls: []call{
+				{"alice", "portainer_restart_container", ""},
+				{"alice", "portainer_restart_container", "tool portainer_restart_container"},
+				{"alice", "portainer_stop_container", ""},
+				{"alice", "portainer_start_container", "caller token alice"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.cfg)
+		for i, c := range tt.calls {
+			ctx := context.Background()
+			if c.caller != "" {
+				ctx = callerContext(c.caller)
+			}
+
+			err := l.LimitToolCall(ctx, toolCall(c.tool))
+			if c.limit == "" {
+				if err != nil {
+					t.Errorf("%s: call %d: %v, want it to run", tt.name, i, err)
+				}
+				continue
+			}
+
+			var rle *mcp.RateLimitError
+			if !errors.As(err, &rle) {
+				t.Errorf("%s: call %d: %v, want %s limit exceeded", tt.name, i, err, c.limit)
+				continue
+			}
+			if rle.Limit != c.limit {
+				t.Errorf("%s: call %d: %s limit exceeded, want %s", tt.name, i, rle.Limit, c.limit)
+			}
+			if rle.RetryAfter <= 0 {
+				t.Errorf("%s: call %d: RetryAfter = %s, want positive", tt.name, i, rle.RetryAfter)
+			}
+		}
+	}
+}
+
+func TestLimitToolCallRetryAfter(t *testing.T) {
+	l := New(config.LimitsConfig{PerCaller: config.RateConfig{PerMinute: 2, Burst: 1}})
+	ctx := callerContext("alice")
+
+	if err := l.LimitToolCall(ctx, toolCall("portainer_list_containers")); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	err := l.LimitToolCall(ctx, toolCall("portainer_list_containers"))
+	var rle *mcp.RateLimitError
+	if !errors.As(err, &rle) {
+		t.Fatalf("second call: %v, want a RateLimitError", err)
+	}
+
+	// Two calls a minute refill a token every 30 seconds
+	if rle.RetryAfter < 29*time.Second || rle.RetryAfter > 30*time.Second {
+		t.Errorf("RetryAfter = %s, want about 30s", rle.RetryAfter)
+	}
+
+	// Refused calls take no token, so waiting is enough to retry
+	err = l.LimitToolCall(ctx, toolCall("portainer_list_containers"))
+	if !errors.As(err, &rle) || rle.RetryAfter > 30*time.Second {
+		t.Errorf("third call: %v, want to retry after at most 30s", err)
+	}
+}
+
+func TestBucketsSweep(t *testing.T) {
+	b := newBuckets(config.RateConfig{PerMinute: 60, Burst: 2}, map[string]config.RateConfig{"unlimited": {}})
+	now := time.Now()
+
+	b.reserve("alice", now)
+	b.reserve("bob", now)
+	b.reserve("bob", now)
+	b.reserve("unlimited", now)
+	if got := len(b.buckets); got != 3 {
+		t.Fatalf("%d buckets, want 3", got)
+	}
+
+	// After a second alice has refilled, bob has not
+	b.sweep(now.Add(time.Second))
+	if _, ok := b.buckets["alice"]; ok {
+		t.Error("full bucket of alice was kept")
+	}
+	if _, ok := b.buckets["unlimited"]; ok {
+		t.Error("unlimited caller was kept")
+	}
+	if _, ok := b.buckets["bob"]; !ok {
+		t.Error("bucket of bob was dropped before refilling")
+	}
+
+	// Reserving sweeps once the interval has passed
+	b.reserve("carol", now.Add(time.Second+bucketSweepInterval))
+	if _, ok := b.buckets["bob"]; ok {
+		t.Error("bucket of bob was kept after refilling")
+	}
+}